@@ -1,8 +1,18 @@
 package api
 
 import (
+	"github.com/Salikhov079/military/api/audit"
+	"github.com/Salikhov079/military/api/cache"
 	"github.com/Salikhov079/military/api/handler"
+	"github.com/Salikhov079/military/api/health"
+	"github.com/Salikhov079/military/api/idempotency"
+	"github.com/Salikhov079/military/api/logging"
+	"github.com/Salikhov079/military/api/metrics"
 	"github.com/Salikhov079/military/api/middleware"
+	"github.com/Salikhov079/military/api/ratelimit"
+	"github.com/Salikhov079/military/api/requestid"
+	"github.com/Salikhov079/military/api/tracing"
+	"github.com/Salikhov079/military/config"
 	_ "github.com/Salikhov079/military/docs"
 
 	"github.com/gin-gonic/gin"
@@ -16,16 +26,54 @@ import (
 // @BasePath /
 // @securityDefinitions.apikey BearerAuth
 // @in header
-// @name Authourization
+// @name Authorization
+// @description Type "Bearer" followed by a space and the access token.
+
+// invalidates lists, per write route, the cached entities it changes besides
+// its own.
+var invalidates = map[string][]string{
+	"/soldier/usebullet": {"bullet"},
+	"/soldier/usefuel":   {"fuel"},
+}
 
 // NewGin sets up a new Gin router with Swagger API endpoints.
-func NewGin(h *handler.Handler) *gin.Engine {
+func NewGin(h *handler.Handler, policy *middleware.Policy, cfg config.Config, idem idempotency.Store, hc *health.Checker, cs cache.Store, rl *ratelimit.Limiter) *gin.Engine {
+
+
+	r := gin.New()
+	// Let handlers pass the gin context to gRPC clients and still carry the
+	// request's trace span, request ID and cancellation.
+	r.ContextWithFallback = true
+	r.Use(tracing.Middleware(cfg.ServiceName))
+	r.Use(requestid.Middleware())
+	r.Use(logging.Middleware(), logging.Recovery())
+	r.Use(metrics.Middleware())
+	r.Use(audit.Middleware(h.Audit))
+	r.Use(middleware.MiddleWare(policy, cfg.LegacyAuthHeader))
+	if rl != nil {
+		r.Use(rl.Middleware())
+	}
+	r.Use(idempotency.Middleware(idem, cfg.IdempotencyTTL))
+	r.Use(audit.Changes(h.AuditSnapshots()))
+	if cs != nil {
+		r.Use(cache.Middleware(cs, cfg.CacheRoutes, invalidates))
+	}
 
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(files.Handler))
 
-	r := gin.Default()
-	r.Use(middleware.MiddleWare())
+	r.GET("/healthz", hc.Healthz)
+	r.GET("/readyz", hc.Readyz)
+	r.GET("/livez", hc.Livez)
+	// Stock counters are not public: they move to the admin listener when
+	// there is one, and are subject to the policy otherwise.
+	if cfg.MetricsPort == "" {
+		r.GET("/metrics", metrics.Handler())
+	}
 
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(files.Handler))
+	auth := r.Group("/auth")
+	auth.POST("/login", h.Login)
+	auth.POST("/refresh", h.Refresh)
+	auth.POST("/logout", h.Logout)
 
 
 	techniques := r.Group("/technique")
@@ -93,8 +141,21 @@ func NewGin(h *handler.Handler) *gin.Engine {
 	bullets.PUT("/add", h.AddBullet)
 	bullets.PUT("/sub", h.SubBullet)
 
+	r.GET("/audit", h.GetAudit)
+
 	r.POST("/ai/chat", h.CHatAi)
+	r.GET("/ai/chat/stream", h.StreamChat)
+	r.DELETE("/ai/chat/stream/:id", h.CancelChatStream)
+	r.GET("/ai/chat/ws", h.StreamChatWS)
+	r.GET("/ai/context", h.GetAIContext)
+	r.GET("/ai/tools", h.ListAITools)
 	r.GET("/ai/gethistory/:id", h.GetHistory)
+	r.POST("/ai/threads", h.CreateThread)
+	r.GET("/ai/threads", h.ListThreads)
+	r.PUT("/ai/threads/:id", h.RenameThread)
+	r.DELETE("/ai/threads/:id", h.DeleteThread)
+	r.GET("/ai/history", h.GetThreadHistory)
 
 	return r
 }
+
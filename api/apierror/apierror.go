@@ -0,0 +1,118 @@
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// StatusClientClosedRequest is the non-standard status used when the client
+// went away before the backend answered.
+const StatusClientClosedRequest = 499
+
+// Response is the error envelope every endpoint returns.
+type Response struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Details   []json.RawMessage `json:"details,omitempty" swaggertype:"array,object"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+var httpStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           StatusClientClosedRequest,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// HTTPStatus returns the HTTP status that corresponds to a gRPC code.
+func HTTPStatus(c codes.Code) int {
+	if s, ok := httpStatus[c]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// Code returns the envelope code for a gRPC code, e.g. "not_found".
+func Code(c codes.Code) string {
+	var b strings.Builder
+	for i, r := range c.String() {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Abort writes an error for code with the matching HTTP status.
+func Abort(ctx *gin.Context, code codes.Code, message string) {
+	AbortWithStatus(ctx, HTTPStatus(code), Code(code), message)
+}
+
+// AbortWithError translates err, usually a gRPC status returned by a
+// backend, into the envelope and the matching HTTP status.
+func AbortWithError(ctx *gin.Context, err error) {
+	httpStatus, res := FromError(err)
+	res.RequestID = RequestID(ctx)
+	ctx.AbortWithStatusJSON(httpStatus, res)
+}
+
+// FromError returns the HTTP status and the envelope, without request ID,
+// for err. Streaming handlers use it for errors that occur after the
+// response has started.
+func FromError(err error) (int, Response) {
+	st, ok := status.FromError(err)
+	if !ok && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		st = status.FromContextError(err)
+	}
+	res := Response{
+		Code:    Code(st.Code()),
+		Message: st.Message(),
+	}
+	for _, d := range st.Proto().GetDetails() {
+		if data, err := protojson.Marshal(d); err == nil {
+			res.Details = append(res.Details, data)
+		}
+	}
+	return HTTPStatus(st.Code()), res
+}
+
+// AbortWithStatus writes an error that has no gRPC counterpart.
+func AbortWithStatus(ctx *gin.Context, httpStatus int, code, message string) {
+	ctx.AbortWithStatusJSON(httpStatus, Response{
+		Code:      code,
+		Message:   message,
+		RequestID: RequestID(ctx),
+	})
+}
+
+// RequestID returns the ID the request ID middleware assigned to the request.
+func RequestID(ctx *gin.Context) string {
+	return ctx.GetString("request_id")
+}
@@ -0,0 +1,56 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidationProblem is the problem type of requests that failed validation.
+const ValidationProblem = "urn:military:problem:validation-error"
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+	RequestID     string         `json:"request_id,omitempty"`
+}
+
+// InvalidParam names a request field and why it was rejected.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// AbortWithValidation writes a 400 application/problem+json response listing
+// the invalid parameters.
+func AbortWithValidation(ctx *gin.Context, detail string, params []InvalidParam) {
+	ctx.Abort()
+	ctx.Render(http.StatusBadRequest, problemRender{Problem{
+		Type:          ValidationProblem,
+		Title:         "Your request parameters didn't validate.",
+		Status:        http.StatusBadRequest,
+		Detail:        detail,
+		Instance:      ctx.Request.URL.Path,
+		InvalidParams: params,
+		RequestID:     RequestID(ctx),
+	}})
+}
+
+type problemRender struct {
+	problem Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header()["Content-Type"] = []string{"application/problem+json"}
+}
@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record is one audited request. Hash covers every other field, including
+// PrevHash, so editing or removing a line breaks the chain after it.
+//
+// Request is the request body with secrets redacted. Changes maps the path
+// of every field of the target that the request changed to a Change; it is
+// set for successful requests on routes with a snapshot. Records written
+// before the request body moved to Request hold it in Changes.
+type Record struct {
+	Seq       int64           `json:"seq"`
+	Time      time.Time       `json:"time"`
+	Actor     string          `json:"actor"`
+	Role      string          `json:"role,omitempty"`
+	Method    string          `json:"method"`
+	Route     string          `json:"route"`
+	Path      string          `json:"path"`
+	Entity    string          `json:"entity"`
+	TargetID  string          `json:"target_id,omitempty"`
+	Request   json.RawMessage `json:"request,omitempty" swaggertype:"object"`
+	Changes   json.RawMessage `json:"changes,omitempty" swaggertype:"object"`
+	Status    int             `json:"status"`
+	Outcome   string          `json:"outcome"`
+	Error     string          `json:"error,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+}
+
+// Filter selects records in Query. Zero fields match everything.
+type Filter struct {
+	Actor  string
+	Entity string
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+// Log is an append-only, hash-chained JSON-lines audit log.
+type Log struct {
+	path string
+
+	mu       sync.Mutex
+	file     *os.File
+	w        *bufio.Writer
+	seq      int64
+	lastHash string
+}
+
+// Open opens the log at path, creating it if needed. The existing chain is
+// verified and Open fails if it has been tampered with.
+func Open(path string) (*Log, error) {
+	l := &Log{path: path}
+	err := l.scan(func(r *Record) error {
+		if r.PrevHash != l.lastHash || r.Hash != hash(r) {
+			return fmt.Errorf("audit log %s: chain broken at seq %d", path, r.Seq)
+		}
+		l.seq, l.lastHash = r.Seq, r.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	l.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	l.w = bufio.NewWriter(l.file)
+	return l, nil
+}
+
+// Append chains r to the previous record and writes it.
+func (l *Log) Append(r *Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+
+	r.Seq = l.seq + 1
+	r.PrevHash = l.lastHash
+	r.Hash = hash(r)
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := l.w.Flush(); err != nil {
+		return err
+	}
+	l.seq, l.lastHash = r.Seq, r.Hash
+	return nil
+}
+
+// Query returns the records matching f, oldest first. When f.Limit is set,
+// only the newest f.Limit matches are returned. Records appended while the
+// file is scanned are not returned.
+func (l *Log) Query(f Filter) ([]Record, error) {
+	// Every Append flushes whole lines, so the file up to its current size
+	// can be read without holding up writers.
+	l.mu.Lock()
+	if l.file == nil {
+		l.mu.Unlock()
+		return nil, errors.New("audit log is closed")
+	}
+	info, err := l.file.Stat()
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	res := []Record{}
+	err = l.scanTo(info.Size(), func(r *Record) error {
+		if f.matches(r) {
+			res = append(res, *r)
+			if f.Limit > 0 && len(res) > f.Limit {
+				res = res[1:]
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+// Close flushes and closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.w.Flush()
+	if serr := l.file.Sync(); err == nil {
+		err = serr
+	}
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.file = nil
+	return err
+}
+
+func (l *Log) scan(fn func(r *Record) error) error {
+	return l.scanTo(-1, fn)
+}
+
+// scanTo calls fn for every record in the first size bytes of the file, or
+// in the whole file when size is negative.
+func (l *Log) scanTo(size int64, fn func(r *Record) error) error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var src io.Reader = f
+	if size >= 0 {
+		src = io.LimitReader(f, size)
+	}
+	dec := json.NewDecoder(src)
+	for {
+		var r Record
+		if err := dec.Decode(&r); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("audit log %s: %w", l.path, err)
+		}
+		if err := fn(&r); err != nil {
+			return err
+		}
+	}
+}
+
+func (f Filter) matches(r *Record) bool {
+	switch {
+	case f.Actor != "" && r.Actor != f.Actor:
+		return false
+	case f.Entity != "" && r.Entity != f.Entity:
+		return false
+	case !f.From.IsZero() && r.Time.Before(f.From):
+		return false
+	case !f.To.IsZero() && r.Time.After(f.To):
+		return false
+	}
+	return true
+}
+
+func hash(r *Record) string {
+	c := *r
+	c.Hash = ""
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
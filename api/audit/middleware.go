@@ -0,0 +1,324 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// redactedFields are never written to the audit log.
+var redactedFields = []string{"password", "refresh_token", "access_token"}
+
+// targetFields name the body fields that identify the target of routes
+// without an :id parameter, in order of preference.
+var targetFields = []string{"id", "soldier_id", "name"}
+
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	if w.body.Len() < 4096 {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// MaxBodySize is the largest request body of a mutating request. Larger
+// bodies are refused with 413 before they reach a handler.
+const MaxBodySize = 1 << 20
+
+// Requests without an authenticated caller, and requests refused by the auth
+// or rate limit middleware, are recorded with at most maxUnverifiedRequest
+// bytes of request body and a target ID of at most maxUnverifiedTarget
+// bytes, so that anonymous callers cannot fill the log.
+const (
+	maxUnverifiedRequest = 1 << 10
+	maxUnverifiedTarget  = 128
+)
+
+// Snapshot loads the current state of the target of a route: the entity with
+// the :id of the route, or the one named by the request body. It returns a
+// NotFound status when the target does not exist.
+type Snapshot func(ctx context.Context, target string) (proto.Message, error)
+
+// Change is the value of one field before and after a request. A field that
+// did not exist on one side is null there.
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Context keys shared by Middleware and Changes.
+const (
+	targetKey  = "audit_target"
+	changesKey = "audit_changes"
+)
+
+// Middleware records every POST, PUT and DELETE request in l once it has
+// been handled, also when a later handler panics. It runs before the auth
+// middleware so that refused requests are recorded too, with a bounded body.
+func Middleware(l *Log) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		method := ctx.Request.Method
+		if method != http.MethodPost && method != http.MethodPut && method != http.MethodDelete {
+			ctx.Next()
+			return
+		}
+
+		var (
+			body    []byte
+			readErr error
+		)
+		if ctx.Request.Body != nil {
+			body, readErr = io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxBodySize))
+			ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		w := &bodyWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = w
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = ctx.Request.URL.Path
+		}
+		request := sanitize(body)
+		r := &Record{
+			Method:   method,
+			Route:    route,
+			Path:     ctx.Request.URL.Path,
+			Entity:   entity(route),
+			TargetID: target(ctx, request),
+			Request:  request,
+
+			RequestID: ctx.GetString("request_id"),
+		}
+		ctx.Set(targetKey, r.TargetID)
+
+		defer func() {
+			p := recover()
+			r.Time = time.Now().UTC()
+			r.Actor = ctx.GetString("user_id")
+			r.Role = ctx.GetString("role")
+			r.Status, r.Outcome = w.Status(), "success"
+			if r.Actor == "" || refused(r.Status) {
+				if len(r.Request) > maxUnverifiedRequest {
+					r.Request = nil
+				}
+				if len(r.TargetID) > maxUnverifiedTarget {
+					r.TargetID = r.TargetID[:maxUnverifiedTarget]
+				}
+			}
+			switch {
+			case p != nil:
+				r.Status, r.Outcome, r.Error = http.StatusInternalServerError, "failure", fmt.Sprint("panic: ", p)
+			case r.Status >= http.StatusBadRequest:
+				r.Outcome, r.Error = "failure", errorMessage(w.body.Bytes())
+			default:
+				if changes, ok := ctx.Get(changesKey); ok {
+					r.Changes = changes.(json.RawMessage)
+				}
+			}
+			if err := l.Append(r); err != nil {
+				slog.ErrorContext(ctx, "Error while writing audit record", "request_id", r.RequestID, "error", err)
+			}
+			if p != nil {
+				panic(p)
+			}
+		}()
+
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(readErr, &tooLarge):
+			apierror.AbortWithStatus(ctx, http.StatusRequestEntityTooLarge, "request_too_large",
+				fmt.Sprintf("request body is larger than %d bytes", MaxBodySize))
+			return
+		case readErr != nil:
+			apierror.Abort(ctx, codes.InvalidArgument, "cannot read request body")
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// refused reports whether a status is the answer of the auth or rate limit
+// middleware.
+func refused(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusTooManyRequests
+}
+
+// Changes loads the target of routes with a snapshot before and after the
+// request, and hands the changed fields of successful requests to
+// Middleware. It runs after the auth middleware, so that only authorized
+// requests cause backend calls.
+func Changes(snapshots map[string]Snapshot) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		snapshot := snapshots[ctx.FullPath()]
+		targetID := ctx.GetString(targetKey)
+		if snapshot == nil || targetID == "" {
+			ctx.Next()
+			return
+		}
+		before := take(ctx, snapshot, targetID)
+		ctx.Next()
+		if before != nil && ctx.Writer.Status() < http.StatusBadRequest {
+			if changes := diff(before, take(ctx, snapshot, targetID)); changes != nil {
+				ctx.Set(changesKey, changes)
+			}
+		}
+	}
+}
+
+// take loads the target as JSON with every field present, so that a field
+// reset to its zero value shows up in the diff. It returns "null" when the
+// target does not exist and nil when it cannot be loaded.
+func take(ctx *gin.Context, snapshot Snapshot, targetID string) json.RawMessage {
+	m, err := snapshot(ctx, targetID)
+	if status.Code(err) == codes.NotFound {
+		return json.RawMessage("null")
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Error while loading audit snapshot", "request_id", ctx.GetString("request_id"), "target_id", targetID, "error", err)
+		return nil
+	}
+	data, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// diff returns the changed fields between two snapshots, keyed by their
+// dotted path, e.g. "quantity" or "bullets.0.quantity". It returns nil when
+// the after snapshot could not be loaded.
+func diff(before, after json.RawMessage) json.RawMessage {
+	if after == nil {
+		return nil
+	}
+	var a, b interface{}
+	if json.Unmarshal(before, &a) != nil || json.Unmarshal(after, &b) != nil {
+		return nil
+	}
+	changes := map[string]Change{}
+	walk("", a, b, changes)
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func walk(path string, a, b interface{}, changes map[string]Change) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	am, aObj := a.(map[string]interface{})
+	bm, bObj := b.(map[string]interface{})
+	al, aList := a.([]interface{})
+	bl, bList := b.([]interface{})
+	switch {
+	case (aObj || a == nil) && (bObj || b == nil) && (aObj || bObj):
+		for k, v := range am {
+			walk(join(k), v, bm[k], changes)
+		}
+		for k, v := range bm {
+			if _, ok := am[k]; !ok {
+				walk(join(k), nil, v, changes)
+			}
+		}
+	case aList && bList:
+		for i := 0; i < len(al) || i < len(bl); i++ {
+			var x, y interface{}
+			if i < len(al) {
+				x = al[i]
+			}
+			if i < len(bl) {
+				y = bl[i]
+			}
+			walk(join(strconv.Itoa(i)), x, y, changes)
+		}
+	case !reflect.DeepEqual(a, b):
+		changes[path] = Change{Before: a, After: b}
+	}
+}
+
+// sanitize returns the JSON body with secrets removed, or nil when the body
+// is not a JSON object.
+func sanitize(body []byte) json.RawMessage {
+	var fields map[string]interface{}
+	if len(body) == 0 || json.Unmarshal(body, &fields) != nil {
+		return nil
+	}
+	for _, key := range redactedFields {
+		if _, ok := fields[key]; ok {
+			fields[key] = "[REDACTED]"
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func target(ctx *gin.Context, request json.RawMessage) string {
+	if id := ctx.Param("id"); id != "" {
+		return id
+	}
+	var fields map[string]interface{}
+	if json.Unmarshal(request, &fields) != nil {
+		return ""
+	}
+	for _, key := range targetFields {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// entity returns the first segment of the route, e.g. "soldier" for
+// "/soldier/delete/:id".
+func entity(route string) string {
+	route = strings.TrimPrefix(route, "/")
+	if i := strings.IndexByte(route, '/'); i >= 0 {
+		return route[:i]
+	}
+	return route
+}
+
+func errorMessage(body []byte) string {
+	var res struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(body, &res) == nil {
+		if res.Message != "" {
+			return res.Message
+		}
+		if res.Error != "" {
+			return res.Error
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Salikhov079/military/api/logging"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l, err := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// stock is the state of the entity the routes change.
+	stock := map[string]interface{}{"type": "weapon", "quantity": 10.0}
+	snapshot := func(ctx context.Context, id string) (proto.Message, error) {
+		if stock == nil {
+			return nil, status.Error(codes.NotFound, "not found")
+		}
+		return structpb.NewStruct(stock)
+	}
+
+	r := gin.New()
+	r.Use(logging.Recovery(), Middleware(l), Changes(map[string]Snapshot{
+		"/bullet/update/:id": snapshot,
+		"/bullet/delete/:id": snapshot,
+	}))
+	r.PUT("/bullet/update/:id", func(ctx *gin.Context) {
+		stock["quantity"] = 7.0
+		ctx.Status(http.StatusOK)
+	})
+	r.DELETE("/bullet/delete/:id", func(ctx *gin.Context) {
+		stock = nil
+		ctx.Status(http.StatusOK)
+	})
+	r.POST("/bullet/create", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		changes            string
+	}{
+		{"PUT", "/bullet/update/b1", `{"quantity":7,"password":"x"}`, http.StatusOK, `{"quantity":{"before":10,"after":7}}`},
+		{"DELETE", "/bullet/delete/b1", "", http.StatusOK, `{"quantity":{"before":7,"after":null},"type":{"before":"weapon","after":null}}`},
+		{"POST", "/bullet/create", `{"type":"weapon"}`, http.StatusInternalServerError, ""},
+		{"POST", "/bullet/create", strings.Repeat("x", MaxBodySize+1), http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.status)
+		}
+	}
+
+	records, err := l.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(tests) {
+		t.Fatalf("got %d records, want %d", len(records), len(tests))
+	}
+	for i, tt := range tests {
+		rec := records[i]
+		if rec.Status != tt.status {
+			t.Errorf("record %d: status = %d, want %d", i, rec.Status, tt.status)
+		}
+		if got := string(rec.Changes); !jsonEqual(got, tt.changes) {
+			t.Errorf("record %d: changes = %s, want %s", i, got, tt.changes)
+		}
+	}
+	if strings.Contains(string(records[0].Request), `"x"`) {
+		t.Errorf("password was not redacted: %s", records[0].Request)
+	}
+	if records[2].Outcome != "failure" || !strings.Contains(records[2].Error, "boom") {
+		t.Errorf("panicking request recorded as %s %q", records[2].Outcome, records[2].Error)
+	}
+}
+
+func TestMiddlewareBoundsUnverifiedRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l, err := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	r := gin.New()
+	r.Use(Middleware(l), func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		ctx.Set("user_id", "c1")
+	})
+	r.POST("/bullet/create", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	body := `{"name":"` + strings.Repeat("x", 2*maxUnverifiedRequest) + `"}`
+	for _, auth := range []string{"", "Bearer t"} {
+		req := httptest.NewRequest("POST", "/bullet/create", strings.NewReader(body))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	records, err := l.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if refused := records[0]; refused.Request != nil || len(refused.TargetID) > maxUnverifiedTarget {
+		t.Errorf("refused request recorded with %d body bytes and a %d byte target", len(refused.Request), len(refused.TargetID))
+	}
+	if accepted := records[1]; accepted.Actor != "c1" || len(accepted.Request) < 2*maxUnverifiedRequest {
+		t.Errorf("authenticated request recorded as %q with %d body bytes", accepted.Actor, len(accepted.Request))
+	}
+}
+
+func jsonEqual(a, b string) bool {
+	if a == "" || b == "" {
+		return a == b
+	}
+	var x, y interface{}
+	return json.Unmarshal([]byte(a), &x) == nil && json.Unmarshal([]byte(b), &y) == nil && reflect.DeepEqual(x, y)
+}
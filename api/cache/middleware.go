@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachedHeaders are stored and replayed with a cached response.
+var cachedHeaders = []string{"Content-Type", "Link"}
+
+type entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	ETag   string      `json:"etag"`
+}
+
+// bufferedWriter holds back the body written by the handler so that the
+// ETag, which depends on the whole body, can still be sent as a header.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Middleware caches successful GET responses of the routes in ttls and
+// answers If-None-Match with 304 Not Modified. Cached entries are keyed by
+// caller, because responses are filtered by the caller's scope.
+//
+// Every route belongs to the entity named by its first path segment. A
+// successful POST, PUT or DELETE invalidates the entries of its entity and of
+// the extra entities listed for its route in invalidates, e.g.
+// "/soldier/usebullet" also changes "bullet".
+func Middleware(store Store, ttls map[string]time.Duration, invalidates map[string][]string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		switch ctx.Request.Method {
+		case http.MethodGet:
+			if ttl := ttls[route]; ttl > 0 {
+				serve(ctx, store, route, ttl)
+				return
+			}
+		case http.MethodPost, http.MethodPut, http.MethodDelete:
+			ctx.Next()
+			if ctx.Writer.Status() < http.StatusBadRequest {
+				invalidate(ctx, store, append([]string{entity(route)}, invalidates[route]...))
+			}
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func serve(ctx *gin.Context, store Store, route string, ttl time.Duration) {
+	key, err := cacheKey(ctx, store, route)
+	if err != nil {
+		logError(ctx, "Error while reading cache generation", err)
+		ctx.Next()
+		return
+	}
+
+	if data, ok, err := store.Get(key); err != nil {
+		logError(ctx, "Error while reading cache", err)
+	} else if ok {
+		var e entry
+		if err := json.Unmarshal(data, &e); err == nil {
+			ctx.Header("X-Cache", "HIT")
+			write(ctx, &e, ttl)
+			return
+		}
+	}
+
+	w := &bufferedWriter{ResponseWriter: ctx.Writer}
+	ctx.Writer = w
+	ctx.Header("X-Cache", "MISS")
+	ctx.Next()
+	ctx.Writer = w.ResponseWriter
+	if w.Status() != http.StatusOK {
+		ctx.Writer.Write(w.body.Bytes())
+		return
+	}
+
+	sum := sha256.Sum256(w.body.Bytes())
+	e := entry{
+		Status: w.Status(),
+		Header: http.Header{},
+		Body:   w.body.Bytes(),
+		ETag:   `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+	for _, h := range cachedHeaders {
+		if v := w.Header().Values(h); len(v) > 0 {
+			e.Header[h] = v
+		}
+	}
+	data, err := json.Marshal(&e)
+	if err == nil {
+		err = store.Set(key, data, ttl)
+	}
+	if err != nil {
+		logError(ctx, "Error while writing cache", err)
+	}
+	write(ctx, &e, ttl)
+}
+
+// write sends e, or 304 Not Modified when the client already has it.
+func write(ctx *gin.Context, e *entry, ttl time.Duration) {
+	for h, v := range e.Header {
+		ctx.Writer.Header()[h] = v
+	}
+	ctx.Header("ETag", e.ETag)
+	ctx.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(ttl.Seconds())))
+	if match(ctx.GetHeader("If-None-Match"), e.ETag) {
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+	ctx.Writer.WriteHeader(e.Status)
+	ctx.Writer.Write(e.Body)
+	ctx.Abort()
+}
+
+func match(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheKey builds the key of the request from the caller, the route, the
+// query string and the current generation of the route's entity.
+func cacheKey(ctx *gin.Context, store Store, route string) (string, error) {
+	gen, err := generation(store, entity(route))
+	if err != nil {
+		return "", err
+	}
+	return "cache:" + ctx.GetString("user_id") + ":" + route + "?" + ctx.Request.URL.Query().Encode() + "#" + gen, nil
+}
+
+func generation(store Store, entity string) (string, error) {
+	v, ok, err := store.Get("cache-gen:" + entity)
+	if err != nil || !ok {
+		return "0", err
+	}
+	return string(v), nil
+}
+
+// invalidate bumps the generation of each entity, orphaning its entries
+// until they expire or are evicted.
+func invalidate(ctx *gin.Context, store Store, entities []string) {
+	for _, e := range entities {
+		if _, err := store.Incr("cache-gen:" + e); err != nil {
+			logError(ctx, "Error while invalidating cache", err)
+		}
+	}
+}
+
+func entity(route string) string {
+	route = strings.TrimPrefix(route, "/")
+	if i := strings.IndexByte(route, '/'); i >= 0 {
+		return route[:i]
+	}
+	return route
+}
+
+func logError(ctx *gin.Context, msg string, err error) {
+	slog.ErrorContext(ctx, msg, "request_id", ctx.GetString("request_id"), "error", err)
+}
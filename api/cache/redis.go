@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Salikhov079/military/api/redis"
+)
+
+// redisStore keeps the cache in a Redis-compatible server, so that it is
+// shared by every gateway replica. The server must implement GET, SET with
+// PX and INCR.
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore returns a store using client.
+func NewRedisStore(client *redis.Client) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Get(key string) ([]byte, bool, error) {
+	v, err := s.client.Do("GET", key)
+	if err != nil || v == nil {
+		return nil, false, err
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", v)
+	}
+	return b, true, nil
+}
+
+func (s *redisStore) Set(key string, value []byte, ttl time.Duration) error {
+	_, err := s.client.Do("SET", key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+func (s *redisStore) Incr(key string) (int64, error) {
+	v, err := s.client.Do("INCR", key)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected INCR reply %T", v)
+	}
+	return n, nil
+}
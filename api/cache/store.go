@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"container/list"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store holds cached responses and the generation counters used to
+// invalidate them.
+type Store interface {
+	// Get returns the value stored under key, if it has not expired.
+	Get(key string) (value []byte, ok bool, err error)
+	// Set stores value under key for ttl.
+	Set(key string, value []byte, ttl time.Duration) error
+	// Incr atomically increments the counter stored under key, which never
+	// expires, and returns the new value.
+	Incr(key string) (int64, error)
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// lruStore is a process-local store that evicts the least recently used
+// entries beyond its capacity.
+type lruStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+// NewLRUStore returns an in-process store holding at most capacity entries.
+func NewLRUStore(capacity int) Store {
+	return &lruStore{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+func (s *lruStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		s.remove(el)
+		return nil, false, nil
+	}
+	s.order.MoveToFront(el)
+	return e.value, true, nil
+}
+
+func (s *lruStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, value, time.Now().Add(ttl))
+	return nil
+}
+
+func (s *lruStore) Incr(key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	if el, ok := s.entries[key]; ok {
+		n, _ = strconv.ParseInt(string(el.Value.(*lruEntry).value), 10, 64)
+	}
+	n++
+	s.set(key, []byte(strconv.FormatInt(n, 10)), time.Time{})
+	return n, nil
+}
+
+func (s *lruStore) set(key string, value []byte, expires time.Time) {
+	if el, ok := s.entries[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value, e.expires = value, expires
+		s.order.MoveToFront(el)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+}
+
+func (s *lruStore) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.entries, el.Value.(*lruEntry).key)
+}
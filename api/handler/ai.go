@@ -1,7 +1,13 @@
 package handler
 
 import (
-	"net/http"
+	"context"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/thread"
+	"github.com/Salikhov079/military/api/validation"
 	pb "github.com/Salikhov079/military/genprotos/ai"
 
 	"github.com/gin-gonic/gin"
@@ -9,48 +15,115 @@ import (
 
 // CHat handles the creation of a new Bullet
 // @Summary      CHAT
-// @Description  CHat with AI
+// @Description  CHat with AI as the caller; user_id, system_context, thread_id and the tool fields in the body are ignored. The message and reply are kept in the thread named by thread_id.
 // @Tags         AI
 // @Accept       json
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        BulletReq  body     pb.AiCHat  true  "Bullet Request"
+// @Param        thread_id  query    string  false  "Thread ID, default when empty"
 // @Success      200        {string} pb.AiCHat       
-// @Failure      401        {string} string        "Error while creating"
+// @Failure      401        {object} apierror.Response "Error while creating"
+// @Failure      404        {object} apierror.Response "Thread not found"
 // @Router       /ai/chat [post]
 func (h *Handler) CHatAi(ctx *gin.Context) {
 	var req pb.AiCHat
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	cctx, tr, th, ok := h.chatContext(ctx, &req)
+	if !ok {
 		return
 	}
-	res, err := h.Ai.CHat(ctx, &req)
+	res, err := tr.chat(cctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(200, res)
+	h.record(cctx, th, req.Text, res.Text)
+	ctx.JSON(200, &pb.AiCHat{Text: res.Text, UserId: res.UserId})
 }
 
 
 // CHat handles the creation of a new Bullet
 // @Summary      GetHistory
-// @Description  CHat with AI
+// @Description  Get the complete chat history the AI service keeps for a user, optionally limited to a time range; messages without a time are left out of a range. id must be the caller's user ID unless the policy allows the caller's role on /users/:user_id/ai. Use /ai/history for the messages of one thread.
 // @Tags         AI
 // @Accept       json
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        id      path    string     true  "User ID"
-// @Success      200        {string} pb.GetHistoryResponse       
-// @Failure      401        {string} string        "Error while creating"
+// @Param        from    query    string  false  "Start time (RFC 3339)"
+// @Param        to      query    string  false  "End time (RFC 3339)"
+// @Param        limit   query    int     false  "Page size, 1 to 100"
+// @Param        offset  query    int     false  "Number of items to skip"
+// @Param        cursor  query    string  false  "next_cursor of the previous page"
+// @Param        sort    query    string  false  "Comma-separated JSON fields, - for descending, e.g. request_text"
+// @Success      200        {object} HistoryPage
+// @Failure      400        {object} apierror.Problem "Invalid query parameter"
+// @Failure      401        {object} apierror.Response "Error while creating"
+// @Failure      403        {object} apierror.Response "Not allowed to read the history of another user"
 // @Router       /ai/gethistory/{id} [get]
 func (h *Handler) GetHistory(ctx *gin.Context) {
 	var req pb.GetHistoryRequest
-	req.Id=ctx.Param("id")
+	var ok bool
+	if req.Id, ok = h.targetUser(ctx, ctx.Param("id")); !ok {
+		return
+	}
+	from, ok := queryTime(ctx, "from")
+	if !ok {
+		return
+	}
+	to, ok := queryTime(ctx, "to")
+	if !ok {
+		return
+	}
 	res, err := h.Ai.GetHistory(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
+		return
+	}
+	page, meta, ok := pagination.Paginate(ctx, inRange(res.Requests, from, to))
+	if !ok {
 		return
 	}
-	ctx.JSON(200, res)
-}
\ No newline at end of file
+	ctx.JSON(200, HistoryPage{Requests: page, Meta: meta})
+}
+
+// inRange returns the messages sent between from and to. Zero times do not
+// limit; when either is set, messages without a valid time are dropped.
+func inRange(all []*pb.GetAllAi, from, to time.Time) []*pb.GetAllAi {
+	if from.IsZero() && to.IsZero() {
+		return all
+	}
+	res := []*pb.GetAllAi{}
+	for _, m := range all {
+		t, err := time.Parse(time.RFC3339, m.Time)
+		if err == nil && (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to)) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// chatContext prepares req for the AI service: it is sent as the caller in
+// the caller's thread with the caller's grounding context and tools, and
+// the runner executes the tool calls with the caller's permissions. On
+// failure the error response is already written and ok is false.
+func (h *Handler) chatContext(ctx *gin.Context, req *pb.AiCHat) (context.Context, *toolRunner, *thread.Thread, bool) {
+	req.UserId = ctx.GetString("user_id")
+	th, ok := h.chatThread(ctx)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	cctx := ctx.Request.Context()
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	tr := &toolRunner{h: h, role: ctx.GetString("role"), actor: req.UserId, scope: sc}
+	req.SystemContext = h.systemContext(cctx, tr.role, sc)
+	req.Tools, req.ToolCalls, req.ToolResults = tr.tools(), nil, nil
+	req.ThreadId = th.ID
+	return cctx, tr, th, true
+}
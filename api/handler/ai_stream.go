@@ -0,0 +1,357 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/stream"
+	"github.com/Salikhov079/military/api/thread"
+	"github.com/Salikhov079/military/api/validation"
+	pb "github.com/Salikhov079/military/genprotos/ai"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// writeWait bounds each write to a streaming client. It is added to the
+// heartbeat interval, so the server's WriteTimeout never cuts a stream.
+const writeWait = 10 * time.Second
+
+// sseRetry is the reconnect delay, in milliseconds, suggested to
+// EventSource clients.
+const sseRetry = 3000
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     checkOrigin,
+}
+
+// streamOrigins are the origins besides the gateway's own whose pages may
+// open chat WebSockets.
+var streamOrigins = map[string]bool{}
+
+// SetStreamOrigins sets the origins, such as "https://app.example.com",
+// whose pages may open chat WebSockets besides the gateway's own.
+func SetStreamOrigins(origins []string) {
+	streamOrigins = map[string]bool{}
+	for _, o := range origins {
+		streamOrigins[strings.ToLower(strings.TrimSuffix(o, "/"))] = true
+	}
+}
+
+// checkOrigin accepts WebSocket handshakes from clients that send no Origin,
+// which are not browsers, from the gateway's own origin and from
+// streamOrigins.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || streamOrigins[strings.ToLower(origin)] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// StreamFrame is one WebSocket message of a chat stream.
+type StreamFrame struct {
+	ID    string      `json:"id,omitempty"`
+	Event string      `json:"event"`
+	Data  interface{} `json:"data,omitempty" swaggertype:"object"`
+}
+
+// StreamChat handles streaming a chat reply over Server-Sent Events
+// @Summary      Stream CHAT
+// @Description  Stream the AI reply as Server-Sent Events: "chunk" events carry partial text and "tool" events the tool calls the AI makes, then one "done" event carries the whole reply or one "error" event a problem. "heartbeat" events are sent while the AI is silent. Reconnect with the Last-Event-ID header, or the last_event_id parameter, to resume a stream within the resume window.
+// @Tags         AI
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        text           query    string  false  "Message, required unless resuming"
+// @Param        thread_id      query    string  false  "Thread ID, default when empty"
+// @Param        access_token   query    string  false  "Access token, for clients that cannot set the Authorization header"
+// @Param        last_event_id  query    string  false  "ID of the last event received"
+// @Param        Last-Event-ID  header   string  false  "ID of the last event received"
+// @Success      200            {string} string  "Event stream"
+// @Success      204            {string} string  "Resumed stream has no more events"
+// @Failure      400            {object} apierror.Problem "Invalid request"
+// @Failure      404            {object} apierror.Response "Stream expired or thread not found"
+// @Router       /ai/chat/stream [get]
+func (h *Handler) StreamChat(ctx *gin.Context) {
+	s, after, ok := h.openStream(ctx, lastEventID(ctx))
+	if !ok {
+		return
+	}
+	s.Attach()
+	defer s.Detach()
+
+	if events, _, finished := s.Events(after); finished && len(events) == 0 {
+		// Tells EventSource to stop reconnecting.
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	rc := http.NewResponseController(ctx.Writer)
+	write := func(format string, args ...interface{}) error {
+		extendDeadline(rc, h.Streams.Heartbeat)
+		if _, err := fmt.Fprintf(ctx.Writer, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if write("retry: %d\n\n", sseRetry) != nil {
+		return
+	}
+	relay(ctx, s, after, h.Streams.Heartbeat, func(id string, e stream.Event) error {
+		if e.Type == stream.Heartbeat {
+			return write("event: %s\ndata: {}\n\n", e.Type)
+		}
+		return write("id: %s\nevent: %s\ndata: %s\n\n", id, e.Type, e.Data)
+	})
+}
+
+// StreamChatWS handles streaming a chat reply over a WebSocket
+// @Summary      Stream CHAT over WebSocket
+// @Description  Upgrade to a WebSocket and stream the AI reply as JSON StreamFrame messages with the same events as /ai/chat/stream. Send {"type":"cancel"} to stop the reply; closing the socket stops it after the resume window. Browsers may connect only from the gateway's origin and the configured ai_stream_origins.
+// @Tags         AI
+// @Security     BearerAuth
+// @Param        text           query    string  false  "Message, required unless resuming"
+// @Param        thread_id      query    string  false  "Thread ID, default when empty"
+// @Param        access_token   query    string  false  "Access token, for clients that cannot set the Authorization header"
+// @Param        last_event_id  query    string  false  "ID of the last event received"
+// @Success      101            {object} StreamFrame  "Switching Protocols"
+// @Failure      400            {object} apierror.Problem "Invalid request"
+// @Failure      403            {object} apierror.Response "Origin not allowed"
+// @Failure      404            {object} apierror.Response "Stream expired or thread not found"
+// @Router       /ai/chat/ws [get]
+func (h *Handler) StreamChatWS(ctx *gin.Context) {
+	if !websocket.IsWebSocketUpgrade(ctx.Request) {
+		apierror.AbortWithValidation(ctx, "expected a WebSocket upgrade request", nil)
+		return
+	}
+	// Checked before the upgrade too, so a refused page starts no stream.
+	if !checkOrigin(ctx.Request) {
+		apierror.Abort(ctx, codes.PermissionDenied, "origin is not allowed to open chat streams")
+		return
+	}
+	s, after, ok := h.openStream(ctx, ctx.Query("last_event_id"))
+	if !ok {
+		return
+	}
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// The upgrader has already written the error response.
+		return
+	}
+	defer conn.Close()
+	s.Attach()
+	defer s.Detach()
+
+	// Read client messages until the socket closes, and treat the close as
+	// the end of the request.
+	rctx, closed := context.WithCancel(ctx.Request.Context())
+	defer closed()
+	heartbeat := h.Streams.Heartbeat
+	conn.SetReadDeadline(time.Now().Add(2*heartbeat + writeWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2*heartbeat + writeWait))
+	})
+	go func() {
+		defer closed()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg struct {
+				Type string `json:"type"`
+			}
+			if json.Unmarshal(data, &msg) != nil {
+				continue
+			}
+			conn.SetReadDeadline(time.Now().Add(2*heartbeat + writeWait))
+			if msg.Type == "cancel" {
+				s.Cancel()
+			}
+		}
+	}()
+
+	relay(rctx, s, after, heartbeat, func(id string, e stream.Event) error {
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if e.Type == stream.Heartbeat {
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return err
+			}
+			return conn.WriteJSON(StreamFrame{Event: e.Type})
+		}
+		return conn.WriteJSON(StreamFrame{ID: id, Event: e.Type, Data: e.Data})
+	})
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// CancelChatStream handles canceling a chat stream
+// @Summary      Cancel Stream CHAT
+// @Description  Stop the AI reply of a stream, e.g. when an EventSource client navigates away
+// @Tags         AI
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path     string  true  "Stream ID, the part of an event ID before the colon"
+// @Success      200  {string} string  "Stream canceled"
+// @Failure      404  {object} apierror.Response "Stream expired"
+// @Router       /ai/chat/stream/{id} [delete]
+func (h *Handler) CancelChatStream(ctx *gin.Context) {
+	s, ok := h.Streams.Get(ctx.Param("id"), ctx.GetString("user_id"))
+	if !ok {
+		apierror.Abort(ctx, codes.NotFound, "stream not found or expired")
+		return
+	}
+	s.Cancel()
+	ctx.JSON(http.StatusOK, "Stream canceled")
+}
+
+// openStream starts a chat stream for the request, or finds the stream
+// being resumed, and returns it with the sequence number to continue after.
+// On failure it writes the error response and returns false.
+func (h *Handler) openStream(ctx *gin.Context, lastEventID string) (*stream.Stream, int, bool) {
+	owner := ctx.GetString("user_id")
+	if lastEventID != "" {
+		id, seq, err := stream.ParseEventID(lastEventID)
+		if err != nil {
+			apierror.AbortWithValidation(ctx, "", []apierror.InvalidParam{{Name: "last_event_id", Reason: "must be an event ID received from this stream"}})
+			return nil, 0, false
+		}
+		s, ok := h.Streams.Get(id, owner)
+		if !ok {
+			apierror.Abort(ctx, codes.NotFound, "stream not found or expired")
+			return nil, 0, false
+		}
+		return s, seq, true
+	}
+
+	req := &pb.AiCHat{Text: ctx.Query("text")}
+	if params := validation.Validate(req); len(params) > 0 {
+		apierror.AbortWithValidation(ctx, "", params)
+		return nil, 0, false
+	}
+	cctx, tr, th, ok := h.chatContext(ctx, req)
+	if !ok {
+		return nil, 0, false
+	}
+	return h.Streams.Start(cctx, owner, h.chatProducer(req, tr, th)), 0, true
+}
+
+// chatProducer streams the reply to req from the AI service. When the AI
+// asks for tool calls at the end of a reply, a "tool" event lists them and
+// the reply continues with their results. Backends that do not implement
+// streaming are asked for the whole reply, which is then sent as a single
+// chunk. A finished reply is recorded in th.
+func (h *Handler) chatProducer(req *pb.AiCHat, tr *toolRunner, th *thread.Thread) func(context.Context, stream.Emit) {
+	return func(ctx context.Context, emit stream.Emit) {
+		var text strings.Builder
+		var results []ToolResult
+		received := false
+		var err error
+		for round := 1; ; round++ {
+			var cs pb.AiService_CHatStreamClient
+			var asked []*pb.ToolCall
+			req.ToolResults = toolResults(results)
+			cs, err = h.Ai.CHatStream(ctx, req)
+			for err == nil {
+				var chunk *pb.AiCHat
+				if chunk, err = cs.Recv(); err == nil {
+					received = true
+					text.WriteString(chunk.Text)
+					asked = append(asked, chunk.ToolCalls...)
+					// A chunk may carry only tool calls, which get their
+					// own event.
+					if chunk.Text != "" {
+						emit(stream.Chunk, &pb.AiCHat{Text: chunk.Text, UserId: chunk.UserId})
+					}
+				}
+			}
+			if err != io.EOF || round > maxToolRounds {
+				break
+			}
+			var calls []ToolCall
+			if calls, err = toolCalls(asked); err != nil || len(calls) == 0 {
+				break
+			}
+			emit(stream.Tool, calls)
+			results = append(results, tr.run(ctx, calls)...)
+		}
+		if status.Code(err) == codes.Unimplemented && !received {
+			var res *pb.AiCHat
+			if res, err = tr.chat(ctx, req); err == nil {
+				emit(stream.Chunk, res)
+				text.WriteString(res.Text)
+				err = io.EOF
+			}
+		}
+		if err != nil && err != io.EOF {
+			_, res := apierror.FromError(err)
+			emit(stream.Error, res)
+			return
+		}
+		h.record(ctx, th, req.Text, text.String())
+		emit(stream.Done, &pb.AiCHat{Text: text.String(), UserId: req.UserId})
+	}
+}
+
+// relay sends the events of s after seq to the client through send until
+// the stream finishes or ctx is done, and a heartbeat after every interval
+// without events.
+func relay(ctx context.Context, s *stream.Stream, after int, interval time.Duration, send func(id string, e stream.Event) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		events, changed, finished := s.Events(after)
+		for _, e := range events {
+			if send(e.ID(s.ID), e) != nil {
+				return
+			}
+			after = e.Seq
+		}
+		if len(events) > 0 {
+			ticker.Reset(interval)
+		}
+		if finished {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		case <-ticker.C:
+			if send("", stream.Event{Type: stream.Heartbeat}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// extendDeadline moves the write deadline of a streaming response past the
+// next heartbeat. Writers that cannot set deadlines are left as they are.
+func extendDeadline(rc *http.ResponseController, heartbeat time.Duration) {
+	rc.SetWriteDeadline(time.Now().Add(heartbeat + writeWait))
+}
+
+func lastEventID(ctx *gin.Context) string {
+	if id := ctx.GetHeader("Last-Event-ID"); id != "" {
+		return id
+	}
+	return ctx.Query("last_event_id")
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	SetStreamOrigins([]string{"https://app.example.com/"})
+	t.Cleanup(func() { SetStreamOrigins(nil) })
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"https://gateway.example.com", true},
+		{"https://App.example.com", true},
+		{"https://evil.example.com", false},
+		{"https://gateway.example.com.evil.example.com", false},
+	}
+	for _, tc := range tests {
+		r := httptest.NewRequest("GET", "http://gateway.example.com/ai/chat/ws", nil)
+		if tc.origin != "" {
+			r.Header.Set("Origin", tc.origin)
+		}
+		if got := checkOrigin(r); got != tc.want {
+			t.Errorf("checkOrigin(%q) = %v, want %v", tc.origin, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/requestid"
+	ai "github.com/Salikhov079/military/genprotos/ai"
+	"github.com/Salikhov079/military/genprotos/militaries"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The tool-calling protocol: the gateway lists the tools the caller may use
+// in AiCHat.tools. The AI service asks for calls in the tool_calls of its
+// reply, and the gateway sends the message again with the results of every
+// call so far in tool_results.
+
+// maxToolRounds bounds how often one chat message may go back to the AI
+// service with tool results.
+const maxToolRounds = 4
+
+// Tool is a read-only gateway query the AI service may call. Callers may use
+// a tool only when their role may GET its route, and the results are limited
+// to their scope as they are on that route.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters" swaggertype:"object"`
+	Route       string          `json:"route"`
+
+	run func(ctx context.Context, h *Handler, sc *scope, args json.RawMessage) (interface{}, error)
+}
+
+// ToolCall is a call the AI service asks for.
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty" swaggertype:"object"`
+}
+
+// ToolResult is the outcome of a ToolCall: either Result or Error is set.
+type ToolResult struct {
+	ID     string             `json:"id"`
+	Name   string             `json:"name"`
+	Result interface{}        `json:"result,omitempty"`
+	Error  *apierror.Response `json:"error,omitempty"`
+}
+
+// ToolList is the response of /ai/tools.
+type ToolList struct {
+	Tools []*Tool `json:"tools"`
+}
+
+// tools is the allow-list of operations the AI service may call. Only
+// reads belong here.
+var tools = []*Tool{
+	{
+		Name:        "list_bullets",
+		Description: "List bullet stocks, optionally of one type such as weapon or military vehicle.",
+		Parameters:  schema(`{"type":{"type":"string"}}`),
+		Route:       "/bullet/getall",
+		run: func(ctx context.Context, h *Handler, _ *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				Type string `json:"type"`
+			}
+			if err := decodeArgs(args, &a); err != nil {
+				return nil, err
+			}
+			res, err := h.BulletService.GetAll(ctx, &militaries.BulletReq{Type: a.Type})
+			if err != nil {
+				return nil, err
+			}
+			return truncate(res.Bullets), nil
+		},
+	},
+	{
+		Name:        "list_fuels",
+		Description: "List fuel stocks in litres, optionally of one type such as diesel or petrol.",
+		Parameters:  schema(`{"type":{"type":"string"}}`),
+		Route:       "/fuel/getall",
+		run: func(ctx context.Context, h *Handler, _ *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				Type string `json:"type"`
+			}
+			if err := decodeArgs(args, &a); err != nil {
+				return nil, err
+			}
+			res, err := h.FuelService.GetAll(ctx, &militaries.FuelReq{Type: a.Type})
+			if err != nil {
+				return nil, err
+			}
+			return truncate(res.Fuels), nil
+		},
+	},
+	{
+		Name:        "list_techniques",
+		Description: "List vehicles and other techniques, optionally of one type or model.",
+		Parameters:  schema(`{"type":{"type":"string"},"model":{"type":"string"}}`),
+		Route:       "/technique/getall",
+		run: func(ctx context.Context, h *Handler, _ *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				Type  string `json:"type"`
+				Model string `json:"model"`
+			}
+			if err := decodeArgs(args, &a); err != nil {
+				return nil, err
+			}
+			res, err := h.TechniqueService.GetAll(ctx, &militaries.TechniqueReq{Type: a.Type, Model: a.Model})
+			if err != nil {
+				return nil, err
+			}
+			return truncate(res.Techniques), nil
+		},
+	},
+	{
+		Name:        "list_soldiers",
+		Description: "List soldiers, optionally of one group or by name. Contact details are not included.",
+		Parameters:  schema(`{"group_id":{"type":"string"},"name":{"type":"string"}}`),
+		Route:       "/soldier/getall",
+		run: func(ctx context.Context, h *Handler, sc *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				GroupID string `json:"group_id"`
+				Name    string `json:"name"`
+			}
+			if err := decodeArgs(args, &a); err != nil {
+				return nil, err
+			}
+			res, err := h.SoldierService.GetAll(ctx, &pb.SoldierReq{GroupId: a.GroupID, Name: a.Name})
+			if err != nil {
+				return nil, err
+			}
+			var soldiers []soldierSummary
+			for _, s := range sc.filterSoldiers(res.Soldiers) {
+				if a.GroupID == "" || s.Group != nil && s.Group.Id == a.GroupID {
+					soldiers = append(soldiers, summarize(s))
+				}
+			}
+			return truncate(soldiers), nil
+		},
+	},
+	{
+		Name:        "get_soldier",
+		Description: "Get one soldier by ID. Contact details are not included.",
+		Parameters:  schema(`{"id":{"type":"string"}}`, "id"),
+		Route:       "/soldier/getbyid/:id",
+		run: func(ctx context.Context, h *Handler, sc *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				ID string `json:"id"`
+			}
+			if err := decodeArgs(args, &a, "id"); err != nil {
+				return nil, err
+			}
+			res, err := h.SoldierService.Get(ctx, &pb.ById{Id: a.ID})
+			if err != nil {
+				return nil, err
+			}
+			if !sc.hasSoldier(res) {
+				return nil, status.Error(codes.PermissionDenied, "soldier is outside of your department")
+			}
+			return summarize(res), nil
+		},
+	},
+	{
+		Name:        "list_groups",
+		Description: "List groups, optionally of one department or by name.",
+		Parameters:  schema(`{"department_id":{"type":"string"},"name":{"type":"string"}}`),
+		Route:       "/group/getall",
+		run: func(ctx context.Context, h *Handler, sc *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				DepartmentID string `json:"department_id"`
+				Name         string `json:"name"`
+			}
+			if err := decodeArgs(args, &a); err != nil {
+				return nil, err
+			}
+			res, err := h.GroupService.GetAll(ctx, &pb.GroupReq{DepartmentId: a.DepartmentID, Name: a.Name})
+			if err != nil {
+				return nil, err
+			}
+			return truncate(sc.filterGroups(res.Groups)), nil
+		},
+	},
+	{
+		Name:        "list_departments",
+		Description: "List departments, optionally by name.",
+		Parameters:  schema(`{"name":{"type":"string"}}`),
+		Route:       "/department/getall",
+		run: func(ctx context.Context, h *Handler, sc *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				Name string `json:"name"`
+			}
+			if err := decodeArgs(args, &a); err != nil {
+				return nil, err
+			}
+			res, err := h.DepartmentService.GetAll(ctx, &pb.Department{Name: a.Name})
+			if err != nil {
+				return nil, err
+			}
+			return truncate(sc.filterDepartments(res.Departments)), nil
+		},
+	},
+	{
+		Name:        "weapon_statistics",
+		Description: "Bullets used by soldiers, optionally on one date (YYYY-MM-DD) or by one soldier.",
+		Parameters:  schema(`{"date":{"type":"string","format":"date"},"soldier_id":{"type":"string"}}`),
+		Route:       "/soldier/getallweaponstatistik",
+		run: func(ctx context.Context, h *Handler, sc *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				Date      string `json:"date"`
+				SoldierID string `json:"soldier_id"`
+			}
+			if err := decodeArgs(args, &a); err != nil {
+				return nil, err
+			}
+			if err := checkDate(a.Date); err != nil {
+				return nil, err
+			}
+			res, err := h.SoldierService.StatistikWeapons(ctx, &pb.GetSoldierStatistik{Date: a.Date, SoldierId: a.SoldierID})
+			if err != nil {
+				return nil, err
+			}
+			used, err := usedInScope(ctx, h, sc, res.UsedWeapons)
+			if err != nil {
+				return nil, err
+			}
+			return truncate(used), nil
+		},
+	},
+	{
+		Name:        "fuel_statistics",
+		Description: "Fuel used by soldiers in litres, optionally on one date (YYYY-MM-DD) or by one soldier.",
+		Parameters:  schema(`{"date":{"type":"string","format":"date"},"soldier_id":{"type":"string"}}`),
+		Route:       "/soldier/getallfuelstatistik",
+		run: func(ctx context.Context, h *Handler, sc *scope, args json.RawMessage) (interface{}, error) {
+			var a struct {
+				Date      string `json:"date"`
+				SoldierID string `json:"soldier_id"`
+			}
+			if err := decodeArgs(args, &a); err != nil {
+				return nil, err
+			}
+			if err := checkDate(a.Date); err != nil {
+				return nil, err
+			}
+			res, err := h.SoldierService.FuelStatistik(ctx, &pb.GetSoldierStatistikFuel{Date: a.Date, SoldierId: a.SoldierID})
+			if err != nil {
+				return nil, err
+			}
+			used, err := usedInScope(ctx, h, sc, res.UsedFuel)
+			if err != nil {
+				return nil, err
+			}
+			return truncate(used), nil
+		},
+	},
+}
+
+var toolsByName = func() map[string]*Tool {
+	m := make(map[string]*Tool, len(tools))
+	for _, t := range tools {
+		m[t.Name] = t
+	}
+	return m
+}()
+
+// soldierSummary is what tools reveal about a soldier.
+type soldierSummary struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	GroupID  string `json:"group_id,omitempty"`
+	Group    string `json:"group,omitempty"`
+	JoinDate string `json:"join_date,omitempty"`
+	EndDate  string `json:"end_date,omitempty"`
+}
+
+func summarize(s *pb.Soldier) soldierSummary {
+	return soldierSummary{
+		ID:       s.Id,
+		Name:     s.Name,
+		GroupID:  s.GetGroup().GetId(),
+		Group:    s.GetGroup().GetName(),
+		JoinDate: s.JoinDate,
+		EndDate:  s.EndDate,
+	}
+}
+
+// ListAITools handles listing the tools the AI may call for the caller
+// @Summary      AI Tools
+// @Description  List the read-only queries the AI may run on behalf of the caller, with the JSON schema of their arguments
+// @Tags         AI
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object} ToolList "Get Successful"
+// @Failure      401  {object} apierror.Response "Unauthorized"
+// @Router       /ai/tools [get]
+func (h *Handler) ListAITools(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, ToolList{Tools: h.toolsFor(ctx.GetString("role"))})
+}
+
+// toolsFor returns the tools whose route role may read.
+func (h *Handler) toolsFor(role string) []*Tool {
+	res := []*Tool{}
+	for _, t := range tools {
+		if h.Policy.Allow(role, http.MethodGet, t.Route) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// toolRunner runs the tool calls of one chat message with the permissions
+// of the caller. It is detached from the gin context, so streams can use it
+// after the handler has returned.
+type toolRunner struct {
+	h     *Handler
+	role  string
+	actor string
+	scope *scope
+}
+
+func (r *toolRunner) run(ctx context.Context, calls []ToolCall) []ToolResult {
+	results := make([]ToolResult, 0, len(calls))
+	for _, c := range calls {
+		res := ToolResult{ID: c.ID, Name: c.Name}
+		v, err := r.call(ctx, c)
+		if err != nil {
+			_, e := apierror.FromError(err)
+			res.Error = &e
+		} else {
+			res.Result = v
+		}
+		slog.InfoContext(ctx, "AI tool call", "request_id", requestid.FromContext(ctx),
+			"actor", r.actor, "role", r.role, "tool", c.Name, "ok", err == nil)
+		results = append(results, res)
+	}
+	return results
+}
+
+func (r *toolRunner) call(ctx context.Context, c ToolCall) (interface{}, error) {
+	t, ok := toolsByName[c.Name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown tool %q", c.Name)
+	}
+	if !r.h.Policy.Allow(r.role, http.MethodGet, t.Route) {
+		return nil, status.Errorf(codes.PermissionDenied, "role %q may not use tool %q", r.role, c.Name)
+	}
+	return t.run(ctx, r.h, r.scope, c.Arguments)
+}
+
+// chat sends req to the AI service and runs the tool calls it asks for,
+// until it answers without calls or maxToolRounds calls have been made.
+func (r *toolRunner) chat(ctx context.Context, req *ai.AiCHat) (*ai.AiCHat, error) {
+	var results []ToolResult
+	for round := 1; ; round++ {
+		req.ToolResults = toolResults(results)
+		res, err := r.h.Ai.CHat(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		calls, err := toolCalls(res.ToolCalls)
+		if err != nil || len(calls) == 0 || round > maxToolRounds {
+			return res, err
+		}
+		results = append(results, r.run(ctx, calls)...)
+	}
+}
+
+// tools returns the tools the caller may use as sent in AiCHat.tools.
+func (r *toolRunner) tools() []*ai.Tool {
+	var res []*ai.Tool
+	for _, t := range r.h.toolsFor(r.role) {
+		res = append(res, &ai.Tool{Name: t.Name, Description: t.Description, Parameters: string(t.Parameters)})
+	}
+	return res
+}
+
+// toolResults converts results to AiCHat.tool_results.
+func toolResults(results []ToolResult) []*ai.ToolResult {
+	var res []*ai.ToolResult
+	for _, r := range results {
+		pr := &ai.ToolResult{Id: r.ID, Name: r.Name}
+		if r.Error != nil {
+			data, _ := json.Marshal(r.Error)
+			pr.Error = string(data)
+		} else if data, err := json.Marshal(r.Result); err != nil {
+			pr.Error = fmt.Sprintf(`{"message":%q}`, err.Error())
+		} else {
+			pr.Result = string(data)
+		}
+		res = append(res, pr)
+	}
+	return res
+}
+
+// toolCalls converts the tool_calls of a reply.
+func toolCalls(calls []*ai.ToolCall) ([]ToolCall, error) {
+	var res []ToolCall
+	for _, c := range calls {
+		tc := ToolCall{ID: c.Id, Name: c.Name}
+		if c.Arguments != "" {
+			if !json.Valid([]byte(c.Arguments)) {
+				return nil, status.Errorf(codes.Internal, "invalid arguments of tool call %q from AI service", c.Name)
+			}
+			tc.Arguments = json.RawMessage(c.Arguments)
+		}
+		res = append(res, tc)
+	}
+	return res, nil
+}
+
+// schema builds the JSON schema of an object with the given properties and
+// required property names. Unknown properties are rejected.
+func schema(properties string, required ...string) json.RawMessage {
+	s := map[string]interface{}{
+		"type":                 "object",
+		"properties":           json.RawMessage(properties),
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// decodeArgs decodes tool arguments strictly into v: unknown properties and
+// missing required ones are errors.
+func decodeArgs(args json.RawMessage, v interface{}, required ...string) error {
+	if len(bytes.TrimSpace(args)) == 0 {
+		args = json.RawMessage("{}")
+	}
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
+	}
+	var present map[string]json.RawMessage
+	json.Unmarshal(args, &present)
+	for _, name := range required {
+		if p, ok := present[name]; !ok || string(p) == `""` || string(p) == "null" {
+			return status.Errorf(codes.InvalidArgument, "invalid arguments: %s is required", name)
+		}
+	}
+	return nil
+}
+
+func checkDate(date string) error {
+	if date == "" {
+		return nil
+	}
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid arguments: date must be YYYY-MM-DD, got %q", strings.TrimSpace(date))
+	}
+	return nil
+}
+
+// truncated is a list cut to pagination.MaxLimit items.
+type truncated[T any] struct {
+	Items     []T  `json:"items"`
+	Total     int  `json:"total"`
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+func truncate[T any](items []T) truncated[T] {
+	res := truncated[T]{Items: items, Total: len(items)}
+	if res.Items == nil {
+		res.Items = []T{}
+	}
+	if len(items) > pagination.MaxLimit {
+		res.Items, res.Truncated = items[:pagination.MaxLimit], true
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Salikhov079/military/api/apierror"
+	ai "github.com/Salikhov079/military/genprotos/ai"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+
+	"google.golang.org/grpc"
+)
+
+type fakeSoldiers struct {
+	pb.SoldierServiceClient
+	soldiers []*pb.Soldier
+}
+
+func (f fakeSoldiers) GetAll(context.Context, *pb.SoldierReq, ...grpc.CallOption) (*pb.AllSoldiers, error) {
+	return &pb.AllSoldiers{Soldiers: f.soldiers}, nil
+}
+
+func TestUsedInScope(t *testing.T) {
+	h := &Handler{SoldierService: fakeSoldiers{soldiers: []*pb.Soldier{
+		{Id: "s1", Group: &pb.Group{Id: "g1"}},
+		{Id: "s2", Group: &pb.Group{Id: "g2"}},
+	}}}
+	used := []*pb.UseB{{SoldierId: "s1"}, {SoldierId: "s2"}, {SoldierId: "s3"}}
+
+	all, err := usedInScope(context.Background(), h, nil, used)
+	if err != nil || len(all) != 3 {
+		t.Errorf("unscoped = %v, %v; want every record", all, err)
+	}
+	sc := &scope{groups: map[string]bool{"g1": true}}
+	scoped, err := usedInScope(context.Background(), h, sc, used)
+	if err != nil || len(scoped) != 1 || scoped[0].SoldierId != "s1" {
+		t.Errorf("scoped = %v, %v; want the records of s1", scoped, err)
+	}
+}
+
+func TestToolMessages(t *testing.T) {
+	calls, err := toolCalls([]*ai.ToolCall{{Id: "c1", Name: "list_bullets", Arguments: `{"type":"weapon"}`}})
+	if err != nil || len(calls) != 1 || string(calls[0].Arguments) != `{"type":"weapon"}` {
+		t.Errorf("toolCalls() = %v, %v", calls, err)
+	}
+	if _, err := toolCalls([]*ai.ToolCall{{Id: "c1", Arguments: "{"}}); err == nil {
+		t.Error("toolCalls() accepted invalid arguments")
+	}
+
+	got := toolResults([]ToolResult{
+		{ID: "c1", Name: "list_bullets", Result: map[string]int{"total": 2}},
+		{ID: "c2", Name: "get_soldier", Error: &apierror.Response{Code: "not_found", Message: "soldier not found"}},
+	})
+	want := []*ai.ToolResult{
+		{Id: "c1", Name: "list_bullets", Result: `{"total":2}`},
+		{Id: "c2", Name: "get_soldier", Error: `{"code":"not_found","message":"soldier not found"}`},
+	}
+	for i := range want {
+		if got[i].Id != want[i].Id || got[i].Result != want[i].Result || got[i].Error != want[i].Error {
+			t.Errorf("toolResults()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if !reflect.DeepEqual(toolResults(nil), []*ai.ToolResult(nil)) {
+		t.Error("toolResults(nil) is not empty")
+	}
+}
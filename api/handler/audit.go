@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/audit"
+	mil "github.com/Salikhov079/military/genprotos/militaries"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+)
+
+// GetAudit handles querying the audit log
+// @Summary      Query Audit Log
+// @Description  Get audit records of mutating requests, oldest first
+// @Tags         Audit
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        actor   query    string  false  "Actor (JWT subject)"
+// @Param        entity  query    string  false  "Entity, e.g. soldier or bullet"
+// @Param        from    query    string  false  "Start time (RFC 3339)"
+// @Param        to      query    string  false  "End time (RFC 3339)"
+// @Param        limit   query    int     false  "Return only the newest N records"
+// @Success      200     {array}  audit.Record  "Get All Successful"
+// @Failure      400     {object} apierror.Problem "Invalid query parameter"
+// @Failure      500     {object} apierror.Response "Error while reading audit log"
+// @Router       /audit [get]
+func (h *Handler) GetAudit(ctx *gin.Context) {
+	f := audit.Filter{
+		Actor:  ctx.Query("actor"),
+		Entity: ctx.Query("entity"),
+	}
+	var err error
+	if from := ctx.Query("from"); from != "" {
+		if f.From, err = time.Parse(time.RFC3339, from); err != nil {
+			apierror.AbortWithValidation(ctx, "", []apierror.InvalidParam{{Name: "from", Reason: "must be an RFC 3339 time"}})
+			return
+		}
+	}
+	if to := ctx.Query("to"); to != "" {
+		if f.To, err = time.Parse(time.RFC3339, to); err != nil {
+			apierror.AbortWithValidation(ctx, "", []apierror.InvalidParam{{Name: "to", Reason: "must be an RFC 3339 time"}})
+			return
+		}
+	}
+	if limit := ctx.Query("limit"); limit != "" {
+		if f.Limit, err = strconv.Atoi(limit); err != nil || f.Limit < 0 {
+			apierror.AbortWithValidation(ctx, "", []apierror.InvalidParam{{Name: "limit", Reason: "must be a non-negative integer"}})
+			return
+		}
+	}
+
+	res, err := h.Audit.Query(f)
+	if err != nil {
+		apierror.AbortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// AuditSnapshots returns how the audit log loads the target of each route
+// whose changes it records: by the :id of update and delete routes, and by
+// the type named in the body of add and sub routes.
+func (h *Handler) AuditSnapshots() map[string]audit.Snapshot {
+	snapshots := map[string]audit.Snapshot{}
+	byID := func(entity string, get func(ctx context.Context, id string) (proto.Message, error)) {
+		snapshots["/"+entity+"/update/:id"] = get
+		snapshots["/"+entity+"/delete/:id"] = get
+	}
+	byID("bullet", func(ctx context.Context, id string) (proto.Message, error) {
+		return h.BulletService.Get(ctx, &mil.ById{Id: id})
+	})
+	byID("fuel", func(ctx context.Context, id string) (proto.Message, error) {
+		return h.FuelService.Get(ctx, &mil.ById{Id: id})
+	})
+	byID("technique", func(ctx context.Context, id string) (proto.Message, error) {
+		return h.TechniqueService.Get(ctx, &mil.ById{Id: id})
+	})
+	byID("soldier", func(ctx context.Context, id string) (proto.Message, error) {
+		return h.SoldierService.Get(ctx, &pb.ById{Id: id})
+	})
+	byID("commander", func(ctx context.Context, id string) (proto.Message, error) {
+		return h.CommanderService.Get(ctx, &pb.ById{Id: id})
+	})
+	byID("department", func(ctx context.Context, id string) (proto.Message, error) {
+		return h.DepartmentService.Get(ctx, &pb.ById{Id: id})
+	})
+	byID("group", func(ctx context.Context, id string) (proto.Message, error) {
+		return h.GroupService.Get(ctx, &pb.ById{Id: id})
+	})
+
+	bullets := func(ctx context.Context, typ string) (proto.Message, error) {
+		res, err := h.BulletService.GetAll(ctx, &mil.BulletReq{Type: typ})
+		if err != nil {
+			return nil, err
+		}
+		all := &mil.AllBullets{}
+		for _, b := range res.Bullets {
+			if b.Type == typ {
+				all.Bullets = append(all.Bullets, b)
+			}
+		}
+		return all, nil
+	}
+	fuels := func(ctx context.Context, typ string) (proto.Message, error) {
+		res, err := h.FuelService.GetAll(ctx, &mil.FuelReq{Type: typ})
+		if err != nil {
+			return nil, err
+		}
+		all := &mil.AllFuels{}
+		for _, f := range res.Fuels {
+			if f.Type == typ {
+				all.Fuels = append(all.Fuels, f)
+			}
+		}
+		return all, nil
+	}
+	techniques := func(ctx context.Context, typ string) (proto.Message, error) {
+		res, err := h.TechniqueService.GetAll(ctx, &mil.TechniqueReq{Type: typ})
+		if err != nil {
+			return nil, err
+		}
+		all := &mil.AllTechnique{}
+		for _, t := range res.Techniques {
+			if t.Type == typ {
+				all.Techniques = append(all.Techniques, t)
+			}
+		}
+		return all, nil
+	}
+	for route, snapshot := range map[string]audit.Snapshot{
+		"/bullet/add":    bullets,
+		"/bullet/sub":    bullets,
+		"/fuel/add":      fuels,
+		"/fuel/sub":      fuels,
+		"/technique/add": techniques,
+		"/technique/sub": techniques,
+	} {
+		snapshots[route] = snapshot
+	}
+	return snapshots
+}
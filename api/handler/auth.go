@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Salikhov079/military/api/apierror"
+	t "github.com/Salikhov079/military/api/token"
+	"github.com/Salikhov079/military/api/validation"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type LoginReq struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshReq struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutReq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// commanderStore authenticates commanders by email. The commander record is
+// looked up in the soldiers service and the password is checked against the
+// credentials file.
+type commanderStore struct {
+	commanders pb.CommanderServiceClient
+	passwords  t.Passwords
+}
+
+// NewCommanderStore returns a credential store for commanders.
+func NewCommanderStore(commanders pb.CommanderServiceClient, passwords t.Passwords) t.CredentialStore {
+	return &commanderStore{commanders: commanders, passwords: passwords}
+}
+
+func (s *commanderStore) Authenticate(ctx context.Context, email, password string) (*t.Principal, error) {
+	cred, err := s.passwords.Check(email, password)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.commanders.GetAll(ctx, &pb.CommanderReq{Email: email})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range res.Commanders {
+		if strings.EqualFold(c.Email, email) {
+			role := cred.Role
+			if role == "" {
+				role = "commander"
+			}
+			return &t.Principal{ID: c.Id, Email: c.Email, Role: role}, nil
+		}
+	}
+	return nil, t.ErrInvalidCredentials
+}
+
+func (s *commanderStore) Lookup(ctx context.Context, email string) (*t.Principal, error) {
+	cred, ok := s.passwords.Get(email)
+	if !ok {
+		return nil, t.ErrUnknownUser
+	}
+
+	res, err := s.commanders.GetAll(ctx, &pb.CommanderReq{Email: email})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range res.Commanders {
+		if strings.EqualFold(c.Email, email) {
+			role := cred.Role
+			if role == "" {
+				role = "commander"
+			}
+			return &t.Principal{ID: c.Id, Email: c.Email, Role: role}, nil
+		}
+	}
+	return nil, t.ErrUnknownUser
+}
+
+// Login handles issuing tokens for valid credentials
+// @Summary      Login
+// @Description  Exchange email and password for an access and a refresh token
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        Login  body     LoginReq  true  "Credentials"
+// @Success      200    {object} t.Tokens  "Login Successful"
+// @Failure      400    {object} apierror.Problem "Invalid request"
+// @Failure      401    {object} apierror.Response "Invalid email or password"
+// @Router       /auth/login [post]
+func (h *Handler) Login(ctx *gin.Context) {
+	var req LoginReq
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	principal, err := h.Credentials.Authenticate(ctx, req.Email, req.Password)
+	if errors.Is(err, t.ErrInvalidCredentials) {
+		apierror.Abort(ctx, codes.Unauthenticated, err.Error())
+		return
+	}
+	if err != nil {
+		apierror.AbortWithError(ctx, err)
+		return
+	}
+	tokens, err := t.GenerateTokens(principal)
+	if err != nil {
+		apierror.AbortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// Refresh handles rotating a refresh token
+// @Summary      Refresh
+// @Description  Exchange a refresh token for a new token pair carrying the user's current role. The presented refresh token is revoked.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        Refresh  body     RefreshReq  true  "Refresh token"
+// @Success      200      {object} t.Tokens    "Refresh Successful"
+// @Failure      400      {object} apierror.Problem "Invalid request"
+// @Failure      401      {object} apierror.Response "Invalid refresh token"
+// @Router       /auth/refresh [post]
+func (h *Handler) Refresh(ctx *gin.Context) {
+	var req RefreshReq
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	tokens, err := t.Refresh(ctx, req.RefreshToken, h.Credentials)
+	if err != nil {
+		if status.Code(err) != codes.Unknown {
+			// The soldiers service failed; the token was not used.
+			apierror.AbortWithError(ctx, err)
+			return
+		}
+		apierror.Abort(ctx, codes.Unauthenticated, err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// Logout handles revoking the caller's tokens
+// @Summary      Logout
+// @Description  Revoke the access token of the request and, when given, the caller's refresh token
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Logout  body     LogoutReq  false  "Refresh token"
+// @Success      200     {string} string     "Logout Successful"
+// @Failure      400     {object} apierror.Response "Invalid refresh token"
+// @Failure      403     {object} apierror.Response "Refresh token of another user"
+// @Router       /auth/logout [post]
+func (h *Handler) Logout(ctx *gin.Context) {
+	var req LogoutReq
+	if ctx.Request.ContentLength > 0 {
+		if !validation.BindJSON(ctx, &req) {
+			return
+		}
+	}
+	subject := ctx.GetString("user_id")
+	if req.RefreshToken != "" {
+		err := t.Revoke(req.RefreshToken, subject)
+		if errors.Is(err, t.ErrForeignToken) {
+			apierror.Abort(ctx, codes.PermissionDenied, err.Error())
+			return
+		}
+		if err != nil {
+			apierror.Abort(ctx, codes.InvalidArgument, err.Error())
+			return
+		}
+	}
+	if err := t.Revoke(ctx.GetString("token"), subject); err != nil {
+		apierror.Abort(ctx, codes.InvalidArgument, err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, "Logout Successful")
+}
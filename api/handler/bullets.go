@@ -4,6 +4,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/metrics"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/validation"
 	pb "github.com/Salikhov079/military/genprotos/militaries"
 
 	"github.com/gin-gonic/gin"
@@ -19,17 +23,16 @@ import (
 // @Security  		BearerAuth
 // @Param        BulletReq  body     pb.BulletReq  true  "Bullet Request"
 // @Success      200        {string} string        "Create Successful"
-// @Failure      401        {string} string        "Error while creating"
+// @Failure      401        {object} apierror.Response "Error while creating"
 // @Router       /bullet/create [post]
 func (h *Handler) CreateBullet(ctx *gin.Context) {
 	var req pb.BulletReq
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
 		return
 	}
 	_, err := h.BulletService.Create(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Create Successful")
@@ -46,18 +49,17 @@ func (h *Handler) CreateBullet(ctx *gin.Context) {
 // @Param        id      path    string   true  "Bullet ID"
 // @Param        Bullet  body    pb.Bullet  true  "Bullet"
 // @Success      200     {string} string  "Update Successful"
-// @Failure      401     {string} string  "Error while updating"
+// @Failure      401     {object} apierror.Response "Error while updating"
 // @Router       /bullet/update/{id} [put]
 func (h *Handler) UpdateBullet(ctx *gin.Context) {
 	var bullet pb.Bullet
-	if err := ctx.ShouldBindJSON(&bullet); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &bullet) {
 		return
 	}
 	bullet.Id = ctx.Param("id")
 	_, err := h.BulletService.Update(ctx, &bullet)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Update Successful")
@@ -72,13 +74,13 @@ func (h *Handler) UpdateBullet(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id      path    string   true  "Bullet ID"
 // @Success      200     {string} string  "Delete Successful"
-// @Failure      401     {string} string  "Error while deleting"
+// @Failure      401     {object} apierror.Response "Error while deleting"
 // @Router       /bullet/delete/{id} [delete]
 func (h *Handler) DeleteBullet(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
 	_, err := h.BulletService.Delete(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Delete Successful")
@@ -93,13 +95,13 @@ func (h *Handler) DeleteBullet(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id      path    string     true  "Bullet ID"
 // @Success      200     {object} pb.Bullet "Get Successful"
-// @Failure      401     {string} string    "Error while getting"
+// @Failure      401     {object} apierror.Response "Error while getting"
 // @Router       /bullet/getbyid/{id} [get]
 func (h *Handler) GetBullet(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
 	res, err := h.BulletService.Get(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -113,8 +115,13 @@ func (h *Handler) GetBullet(ctx *gin.Context) {
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        query  query   pb.BulletReq  true  "Query parameter"
-// @Success      200    {object} pb.AllBullets "Get All Successful"
-// @Failure      401    {string} string       "Error while getting all"
+// @Param        limit   query    int     false  "Page size, 1 to 100"
+// @Param        offset  query    int     false  "Number of items to skip"
+// @Param        cursor  query    string  false  "next_cursor of the previous page"
+// @Param        sort    query    string  false  "Comma-separated JSON fields, - for descending, e.g. name,-quantity"
+// @Success      200    {object} BulletPage "Get All Successful"
+// @Failure      400    {object} apierror.Problem "Invalid pagination parameter"
+// @Failure      401    {object} apierror.Response "Error while getting all"
 // @Router       /bullet/getall [get]
 func (h *Handler) GetAllBullets(ctx *gin.Context) {
 	cl := ctx.Query("caliber")
@@ -126,10 +133,14 @@ func (h *Handler) GetAllBullets(ctx *gin.Context) {
 
 	res, err := h.BulletService.GetAll(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, res)
+	page, meta, ok := pagination.Paginate(ctx, res.Bullets)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, BulletPage{Bullets: page, Meta: meta})
 }
 
 // Add handles adding quantity to a Bullet
@@ -141,18 +152,17 @@ func (h *Handler) GetAllBullets(ctx *gin.Context) {
 // @Security     BearerAuth
 // @Param        Bullet body pb.BulletAddSub true "Bullet data"
 // @Success      200    {object} pb.Void "Add Successful"
-// @Failure      500    {string} string  "Error while adding quantity"
+// @Failure      500    {object} apierror.Response "Error while adding quantity"
 // @Router       /bullet/add [put]
 func (h *Handler) AddBullet(ctx *gin.Context) {
 	var Bullet pb.BulletAddSub
-	if err := ctx.ShouldBindJSON(&Bullet); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &Bullet) {
 		return
 	}
 
 	_, err := h.BulletService.Add(ctx, &Bullet)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Updated")
@@ -167,19 +177,19 @@ func (h *Handler) AddBullet(ctx *gin.Context) {
 // @Security     BearerAuth
 // @Param        Bullet body pb.BulletAddSub true "Bullet data"
 // @Success      200    {object} pb.Void "Subtract Successful"
-// @Failure      500    {string} string  "Error while subtracting quantity"
+// @Failure      500    {object} apierror.Response "Error while subtracting quantity"
 // @Router       /bullet/sub [put]
 func (h *Handler) SubBullet(ctx *gin.Context) {
 	var Bullet pb.BulletAddSub
-	if err := ctx.ShouldBindJSON(&Bullet); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &Bullet) {
 		return
 	}
 
 	_, err := h.BulletService.Sub(ctx, &Bullet)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
+	metrics.BulletsSubtracted(Bullet.Name, metrics.SourceSub, Bullet.Quantity)
 	ctx.JSON(http.StatusOK, "Updated")
 }
\ No newline at end of file
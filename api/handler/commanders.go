@@ -3,6 +3,9 @@ package handler
 import (
 	"net/http"
 
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/validation"
 	pb "github.com/Salikhov079/military/genprotos/soldiers"
 
 	"github.com/gin-gonic/gin"
@@ -17,17 +20,16 @@ import (
 // @Security  		BearerAuth
 // @Param        CommanderReq  body     pb.CreateCommand  true  "Commander Request"
 // @Success      200           {string} string           "Create Successful"
-// @Failure      401           {string} string           "Error while creating"
+// @Failure      401           {object} apierror.Response "Error while creating"
 // @Router       /commander/create [post]
 func (h *Handler) CreateCommander(ctx *gin.Context) {
 	var req pb.CommanderReq
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
 		return
 	}
 	_, err := h.CommanderService.Create(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Create Successful")
@@ -43,18 +45,17 @@ func (h *Handler) CreateCommander(ctx *gin.Context) {
 // @Param        id          path     string           true  "Commander ID"
 // @Param        Commander   body     pb.Commander     true  "Commander"
 // @Success      200         {string} string           "Update Successful"
-// @Failure      401         {string} string           "Error while updating"
+// @Failure      401         {object} apierror.Response "Error while updating"
 // @Router       /commander/update/{id} [put]
 func (h *Handler) UpdateCommander(ctx *gin.Context) {
 	var commander pb.Commander
-	if err := ctx.ShouldBindJSON(&commander); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &commander) {
 		return
 	}
 	commander.Id = ctx.Param("id")
 	_, err := h.CommanderService.Update(ctx, &commander)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Update Successful")
@@ -69,13 +70,13 @@ func (h *Handler) UpdateCommander(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id       path     string   true  "Commander ID"
 // @Success      200      {string} string  "Delete Successful"
-// @Failure      401      {string} string  "Error while deleting"
+// @Failure      401      {object} apierror.Response "Error while deleting"
 // @Router       /commander/delete/{id} [delete]
 func (h *Handler) DeleteCommander(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
 	_, err := h.CommanderService.Delete(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Delete Successful")
@@ -90,13 +91,13 @@ func (h *Handler) DeleteCommander(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id       path     string      true  "Commander ID"
 // @Success      200      {object} pb.Commander "Get Successful"
-// @Failure      401      {string} string       "Error while getting"
+// @Failure      401      {object} apierror.Response "Error while getting"
 // @Router       /commander/get/{id} [get]
 func (h *Handler) GetCommander(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
 	res, err := h.CommanderService.Get(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -110,8 +111,13 @@ func (h *Handler) GetCommander(ctx *gin.Context) {
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        query  query    pb.GetAllFilter  true  "Query parameter"
-// @Success      200    {object} pb.AllCommanders "Get All Successful"
-// @Failure      401    {string} string           "Error while getting all"
+// @Param        limit   query    int     false  "Page size, 1 to 100"
+// @Param        offset  query    int     false  "Number of items to skip"
+// @Param        cursor  query    string  false  "next_cursor of the previous page"
+// @Param        sort    query    string  false  "Comma-separated JSON fields, - for descending, e.g. name,-quantity"
+// @Success      200    {object} CommanderPage "Get All Successful"
+// @Failure      400    {object} apierror.Problem "Invalid pagination parameter"
+// @Failure      401    {object} apierror.Response "Error while getting all"
 // @Router       /commander/getall [get]
 func (h *Handler) GetAllCommanders(ctx *gin.Context) {
 	email := ctx.Query("email")
@@ -119,8 +125,12 @@ func (h *Handler) GetAllCommanders(ctx *gin.Context) {
 	req := pb.CommanderReq{Email: email, Name: name}
 	res, err := h.CommanderService.GetAll(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, res)
+	page, meta, ok := pagination.Paginate(ctx, res.Commanders)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, CommanderPage{Commanders: page, Meta: meta})
 }
@@ -2,6 +2,9 @@ package handler
 
 import (
 	"net/http"
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/validation"
 	pb "github.com/Salikhov079/military/genprotos/soldiers"
 
 	"github.com/gin-gonic/gin"
@@ -18,17 +21,24 @@ import (
 // @Security  		BearerAuth
 // @Param        Department  body     pb.CreateDeportment  true  "Department"
 // @Success      200         {string} string         "Create Successful"
-// @Failure      401         {string} string         "Error while creating"
+// @Failure      401         {object} apierror.Response "Error while creating"
 // @Router       /department/create [post]
 func (h *Handler) CreateDepartment(ctx *gin.Context) {
 	var dept pb.Department
-	if err := ctx.ShouldBindJSON(&dept); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &dept) {
+		return
+	}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.ownsDepartment(&dept) {
+		outOfScope(ctx, "department")
 		return
 	}
 	_, err := h.DepartmentService.Create(ctx, &dept)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Create Successful")
@@ -44,18 +54,25 @@ func (h *Handler) CreateDepartment(ctx *gin.Context) {
 // @Param        id         path     string         true  "Department ID"
 // @Param        Department body     pb.Department  true  "Department"
 // @Success      200        {string} string         "Update Successful"
-// @Failure      401        {string} string         "Error while updating"
+// @Failure      401        {object} apierror.Response "Error while updating"
 // @Router       /department/update/{id} [put]
 func (h *Handler) UpdateDepartment(ctx *gin.Context) {
 	var dept pb.Department
-	if err := ctx.ShouldBindJSON(&dept); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &dept) {
 		return
 	}
 	dept.Id = ctx.Param("id")
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.hasDepartment(dept.Id) || (dept.Commander != nil && !sc.ownsDepartment(&dept)) {
+		outOfScope(ctx, "department")
+		return
+	}
 	_, err := h.DepartmentService.Update(ctx, &dept)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Update Successful")
@@ -70,13 +87,21 @@ func (h *Handler) UpdateDepartment(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id     path     string   true  "Department ID"
 // @Success      200    {string} string  "Delete Successful"
-// @Failure      401    {string} string  "Error while deleting"
+// @Failure      401    {object} apierror.Response "Error while deleting"
 // @Router       /department/delete/{id} [delete]
 func (h *Handler) DeleteDepartment(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.hasDepartment(id.Id) {
+		outOfScope(ctx, "department")
+		return
+	}
 	_, err := h.DepartmentService.Delete(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Delete Successful")
@@ -91,13 +116,21 @@ func (h *Handler) DeleteDepartment(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id     path     string      true  "Department ID"
 // @Success      200    {object} pb.Department "Get Successful"
-// @Failure      401    {string} string       "Error while getting"
+// @Failure      401    {object} apierror.Response "Error while getting"
 // @Router       /department/get/{id} [get]
 func (h *Handler) GetDepartment(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.hasDepartment(id.Id) {
+		outOfScope(ctx, "department")
+		return
+	}
 	res, err := h.DepartmentService.Get(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -111,16 +144,30 @@ func (h *Handler) GetDepartment(ctx *gin.Context) {
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        query  query    pb.GetAllDepartmentFilter  true  "Query parameter"
-// @Success      200    {object} pb.AllDepartments "Get All Successful"
-// @Failure      401    {string} string           "Error while getting all"
+// @Param        limit   query    int     false  "Page size, 1 to 100"
+// @Param        offset  query    int     false  "Number of items to skip"
+// @Param        cursor  query    string  false  "next_cursor of the previous page"
+// @Param        sort    query    string  false  "Comma-separated JSON fields, - for descending, e.g. name,-quantity"
+// @Success      200    {object} DepartmentPage "Get All Successful"
+// @Failure      400    {object} apierror.Problem "Invalid pagination parameter"
+// @Failure      401    {object} apierror.Response "Error while getting all"
 // @Router       /department/getall [get]
 func (h *Handler) GetAllDepartments(ctx *gin.Context) {
 	name := ctx.Query("name")
 	req := pb.Department{Name: name}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
 	res, err := h.DepartmentService.GetAll(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, res)
+	res.Departments = sc.filterDepartments(res.Departments)
+	page, meta, ok := pagination.Paginate(ctx, res.Departments)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, DepartmentPage{Departments: page, Meta: meta})
 }
@@ -4,6 +4,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/metrics"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/validation"
 	pb "github.com/Salikhov079/military/genprotos/militaries"
 
 	"github.com/gin-gonic/gin"
@@ -18,17 +22,16 @@ import (
 // @Security  		BearerAuth
 // @Param        FuelReq  body     pb.FuelReq  true  "Fuel Request"
 // @Success      200      {string} string      "Create Successful"
-// @Failure      400      {string} string      "Error while creating"
+// @Failure      400      {object} apierror.Problem "Error while creating"
 // @Router       /fuel/create [post]
 func (h *Handler) CreateFuel(ctx *gin.Context) {
 	var req pb.FuelReq
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
 		return
 	}
 	_, err := h.FuelService.Create(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Create Successful")
@@ -44,18 +47,17 @@ func (h *Handler) CreateFuel(ctx *gin.Context) {
 // @Param        id    path     string    true  "Fuel ID"
 // @Param        Fuel  body     pb.Fuel   true  "Fuel"
 // @Success      200   {string} string    "Update Successful"
-// @Failure      400   {string} string    "Error while updating"
+// @Failure      400   {object} apierror.Problem "Error while updating"
 // @Router       /fuel/update/{id} [put]
 func (h *Handler) UpdateFuel(ctx *gin.Context) {
 	var fuel pb.Fuel
-	if err := ctx.ShouldBindJSON(&fuel); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &fuel) {
 		return
 	}
 	fuel.Id = ctx.Param("id")
 	_, err := h.FuelService.Update(ctx, &fuel)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Update Successful")
@@ -70,13 +72,13 @@ func (h *Handler) UpdateFuel(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id    path     string    true  "Fuel ID"
 // @Success      200   {string} string    "Delete Successful"
-// @Failure      400   {string} string    "Error while deleting"
+// @Failure      400   {object} apierror.Response "Error while deleting"
 // @Router       /fuel/delete/{id} [delete]
 func (h *Handler) DeleteFuel(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
 	_, err := h.FuelService.Delete(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Delete Successful")
@@ -91,13 +93,13 @@ func (h *Handler) DeleteFuel(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id    path     string    true  "Fuel ID"
 // @Success      200   {object} pb.Fuel   "Get Successful"
-// @Failure      400   {string} string    "Error while getting"
+// @Failure      400   {object} apierror.Response "Error while getting"
 // @Router       /fuel/getbyid/{id} [get]
 func (h *Handler) GetFuel(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
 	res, err := h.FuelService.Get(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -111,8 +113,13 @@ func (h *Handler) GetFuel(ctx *gin.Context) {
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        query  query    pb.FuelReq  true  "Query parameter"
-// @Success      200    {object} pb.AllFuels "Get All Successful"
-// @Failure      400    {string} string      "Error while getting all"
+// @Param        limit   query    int     false  "Page size, 1 to 100"
+// @Param        offset  query    int     false  "Number of items to skip"
+// @Param        cursor  query    string  false  "next_cursor of the previous page"
+// @Param        sort    query    string  false  "Comma-separated JSON fields, - for descending, e.g. name,-quantity"
+// @Success      200    {object} FuelPage "Get All Successful"
+// @Failure      400    {object} apierror.Problem "Invalid pagination parameter"
+// @Failure      400    {object} apierror.Response "Error while getting all"
 // @Router       /fuel/getall [get]
 func (h *Handler) GetAllFuels(ctx *gin.Context) {
 	qu := ctx.Query("quantity")
@@ -121,10 +128,14 @@ func (h *Handler) GetAllFuels(ctx *gin.Context) {
 	req := pb.FuelReq{Quantity: int32(q), Type: ty}
 	res, err := h.FuelService.GetAll(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, res)
+	page, meta, ok := pagination.Paginate(ctx, res.Fuels)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, FuelPage{Fuels: page, Meta: meta})
 }
 
 // Add handles adding quantity to a Fuel
@@ -136,18 +147,17 @@ func (h *Handler) GetAllFuels(ctx *gin.Context) {
 // @Security     BearerAuth
 // @Param        Fuel body pb.FuelAddSub true "Fuel data"
 // @Success      200    {object} pb.Void "Add Successful"
-// @Failure      500    {string} string  "Error while adding quantity"
+// @Failure      500    {object} apierror.Response "Error while adding quantity"
 // @Router       /fuel/add [put]
 func (h *Handler) AddFuel(ctx *gin.Context) {
 	var Fuel pb.FuelAddSub
-	if err := ctx.ShouldBindJSON(&Fuel); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &Fuel) {
 		return
 	}
 
 	_, err := h.FuelService.Add(ctx, &Fuel)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Updated")
@@ -162,19 +172,19 @@ func (h *Handler) AddFuel(ctx *gin.Context) {
 // @Security     BearerAuth
 // @Param        Fuel body pb.FuelAddSub true "Fuel data"
 // @Success      200    {object} pb.Void "Subtract Successful"
-// @Failure      500    {string} string  "Error while subtracting quantity"
+// @Failure      500    {object} apierror.Response "Error while subtracting quantity"
 // @Router       /fuel/sub [put]
 func (h *Handler) SubFuel(ctx *gin.Context) {
 	var Fuel pb.FuelAddSub
-	if err := ctx.ShouldBindJSON(&Fuel); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &Fuel) {
 		return
 	}
 
 	_, err := h.FuelService.Sub(ctx, &Fuel)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
+	metrics.FuelConsumed(Fuel.Name, metrics.SourceSub, Fuel.Quantity)
 	ctx.JSON(http.StatusOK, "Updated")
 }
\ No newline at end of file
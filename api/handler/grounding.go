@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Salikhov079/military/api/requestid"
+	"github.com/Salikhov079/military/genprotos/militaries"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// groundingWindow is how far back usage is summed to estimate daily use.
+const groundingWindow = 7
+
+// GroundingContext is the live data the AI answers from. Each section is
+// included only when the caller's role may read its routes, and usage is
+// limited to the caller's soldiers when the caller is scoped to departments.
+type GroundingContext struct {
+	GeneratedAt time.Time  `json:"generated_at"`
+	Role        string     `json:"role"`
+	Scope       string     `json:"scope" enums:"all,departments"`
+	WindowDays  int        `json:"window_days"`
+	Bullets     []Supply   `json:"bullets,omitempty"`
+	Fuels       []Supply   `json:"fuels,omitempty"`
+	Techniques  []Supply   `json:"techniques,omitempty"`
+	Personnel   *Personnel `json:"personnel,omitempty"`
+	// Unavailable names the sections whose backend could not be reached.
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// Supply is the stock of one type of supply. Usage is known for bullets and
+// fuel only; DaysLeft is the stock divided by the average daily use over
+// the window.
+type Supply struct {
+	Type     string   `json:"type"`
+	Stock    int64    `json:"stock"`
+	Used     int64    `json:"used_in_window,omitempty"`
+	DailyUse float64  `json:"daily_use,omitempty"`
+	DaysLeft *float64 `json:"days_left,omitempty"`
+}
+
+// Personnel counts the soldiers in the caller's scope and the groups and
+// departments they serve in.
+type Personnel struct {
+	Soldiers    int `json:"soldiers"`
+	Groups      int `json:"groups"`
+	Departments int `json:"departments"`
+}
+
+// GetAIContext handles showing the grounding context of the caller
+// @Summary      AI Context
+// @Description  Get the live inventory and personnel data sent to the AI with every chat message of the caller
+// @Tags         AI
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object} GroundingContext "Get Successful"
+// @Failure      401  {object} apierror.Response "Unauthorized"
+// @Router       /ai/context [get]
+func (h *Handler) GetAIContext(ctx *gin.Context) {
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, h.grounding(ctx.Request.Context(), ctx.GetString("role"), sc))
+}
+
+// systemContext returns the grounding context of a caller as the JSON sent
+// in AiCHat.system_context.
+func (h *Handler) systemContext(ctx context.Context, role string, sc *scope) string {
+	data, err := json.Marshal(h.grounding(ctx, role, sc))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// grounding loads the grounding context of a caller. Sections whose backend
+// fails are listed as unavailable.
+func (h *Handler) grounding(ctx context.Context, role string, sc *scope) *GroundingContext {
+	g := &GroundingContext{
+		GeneratedAt: time.Now().UTC(),
+		Role:        role,
+		Scope:       "all",
+		WindowDays:  groundingWindow,
+	}
+	if sc != nil {
+		g.Scope = "departments"
+	}
+	can := func(route string) bool { return h.Policy.Allow(role, http.MethodGet, route) }
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		bullets    *militaries.AllBullets
+		fuels      *militaries.AllFuels
+		techniques *militaries.AllTechnique
+		soldiers   *pb.AllSoldiers
+		weaponUse  *pb.GetSoldierStatistikRes
+		fuelUse    *pb.GetSoldierStatistikFuelRes
+	)
+	fetch := func(section string, allowed bool, call func() error) {
+		if !allowed {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := call(); err != nil {
+				slog.WarnContext(ctx, "Error while loading AI grounding context", "request_id", requestid.FromContext(ctx), "section", section, "error", err)
+				mu.Lock()
+				g.Unavailable = append(g.Unavailable, section)
+				mu.Unlock()
+			}
+		}()
+	}
+	fetch("bullets", can("/bullet/getall"), func() (err error) {
+		bullets, err = h.BulletService.GetAll(ctx, &militaries.BulletReq{})
+		return err
+	})
+	fetch("fuels", can("/fuel/getall"), func() (err error) {
+		fuels, err = h.FuelService.GetAll(ctx, &militaries.FuelReq{})
+		return err
+	})
+	fetch("techniques", can("/technique/getall"), func() (err error) {
+		techniques, err = h.TechniqueService.GetAll(ctx, &militaries.TechniqueReq{})
+		return err
+	})
+	// Scoped usage is filtered by soldier, so scoped callers need the
+	// soldiers even when they may not list them.
+	fetch("personnel", can("/soldier/getall") || sc != nil, func() (err error) {
+		soldiers, err = h.SoldierService.GetAll(ctx, &pb.SoldierReq{})
+		return err
+	})
+	fetch("weapon usage", can("/soldier/getallweaponstatistik"), func() (err error) {
+		weaponUse, err = h.SoldierService.StatistikWeapons(ctx, &pb.GetSoldierStatistik{})
+		return err
+	})
+	fetch("fuel usage", can("/soldier/getallfuelstatistik"), func() (err error) {
+		fuelUse, err = h.SoldierService.FuelStatistik(ctx, &pb.GetSoldierStatistikFuel{})
+		return err
+	})
+	wg.Wait()
+	sort.Strings(g.Unavailable)
+
+	// Usage of soldiers outside the scope is dropped; when the soldiers
+	// could not be loaded, so is all usage of a scoped caller.
+	inScope := func(soldierID string) bool { return true }
+	if sc != nil {
+		ids := map[string]bool{}
+		if soldiers != nil {
+			for _, s := range sc.filterSoldiers(soldiers.Soldiers) {
+				ids[s.Id] = true
+			}
+		}
+		inScope = func(soldierID string) bool { return ids[soldierID] }
+	}
+	since := g.GeneratedAt.AddDate(0, 0, -groundingWindow)
+	recent := func(date string) bool {
+		t, ok := usageDate(date)
+		return ok && !t.Before(since)
+	}
+
+	if bullets != nil {
+		used := map[string]int64{}
+		for _, u := range weaponUse.GetUsedWeapons() {
+			if inScope(u.SoldierId) && recent(u.Date) {
+				used["weapon"] += int64(u.QuantityWeapon)
+				used["military vehicle"] += int64(u.QuantityBigWeapon)
+			}
+		}
+		stock := map[string]int64{}
+		for _, b := range bullets.Bullets {
+			stock[b.Type] += int64(b.Quantity)
+		}
+		g.Bullets = supplies(stock, used, weaponUse != nil)
+	}
+	if fuels != nil {
+		used := map[string]int64{}
+		for _, u := range fuelUse.GetUsedFuel() {
+			if inScope(u.SoldierId) && recent(u.Date) {
+				used["diesel"] += int64(u.Diesel)
+				used["petrol"] += int64(u.Petrol)
+			}
+		}
+		stock := map[string]int64{}
+		for _, f := range fuels.Fuels {
+			stock[f.Type] += int64(f.Quantity)
+		}
+		g.Fuels = supplies(stock, used, fuelUse != nil)
+	}
+	if techniques != nil {
+		stock := map[string]int64{}
+		for _, t := range techniques.Techniques {
+			stock[t.Type] += int64(t.Quantity)
+		}
+		g.Techniques = supplies(stock, nil, false)
+	}
+	if soldiers != nil && can("/soldier/getall") {
+		g.Personnel = personnel(sc, soldiers.Soldiers)
+	}
+	return g
+}
+
+// supplies turns stock and usage per type into supplies sorted by type.
+// Types without stock are listed when they were used. Without usage data,
+// no estimate is made.
+func supplies(stock, used map[string]int64, withUsage bool) []Supply {
+	for typ := range used {
+		if _, ok := stock[typ]; !ok && used[typ] > 0 {
+			stock[typ] = 0
+		}
+	}
+	res := make([]Supply, 0, len(stock))
+	for typ, n := range stock {
+		s := Supply{Type: typ, Stock: n}
+		if withUsage {
+			s.Used = used[typ]
+			s.DailyUse = round1(float64(s.Used) / groundingWindow)
+			if s.Used > 0 {
+				days := round1(float64(n) / (float64(s.Used) / groundingWindow))
+				s.DaysLeft = &days
+			}
+		}
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Type < res[j].Type })
+	return res
+}
+
+func personnel(sc *scope, soldiers []*pb.Soldier) *Personnel {
+	p := &Personnel{}
+	groups := map[string]bool{}
+	departments := map[string]bool{}
+	for _, s := range sc.filterSoldiers(soldiers) {
+		p.Soldiers++
+		if s.Group != nil {
+			groups[s.Group.Id] = true
+			if s.Group.Department != nil {
+				departments[s.Group.Department.Id] = true
+			}
+		}
+	}
+	p.Groups, p.Departments = len(groups), len(departments)
+	return p
+}
+
+// usageDate parses the date of a usage record, which the soldiers service
+// returns either as a date or as a timestamp.
+func usageDate(s string) (time.Time, bool) {
+	if len(s) < len(time.DateOnly) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.DateOnly, s[:len(time.DateOnly)])
+	return t, err == nil
+}
+
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
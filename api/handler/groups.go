@@ -2,6 +2,9 @@ package handler
 
 import (
 	"net/http"
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/validation"
 	pb "github.com/Salikhov079/military/genprotos/soldiers"
 
 	"github.com/gin-gonic/gin"
@@ -16,17 +19,24 @@ import (
 // @Security  		BearerAuth
 // @Param        GroupReq  body     pb.GroupReq  true  "Group Request"
 // @Success      200       {string} string       "Create Successful"
-// @Failure      401       {string} string       "Error while creating"
+// @Failure      401       {object} apierror.Response "Error while creating"
 // @Router       /group/create [post]
 func (h *Handler) CreateGroup(ctx *gin.Context) {
 	var req pb.GroupReq
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.hasDepartment(req.DepartmentId) {
+		outOfScope(ctx, "department")
 		return
 	}
 	_, err := h.GroupService.Create(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Create Successful")
@@ -42,18 +52,29 @@ func (h *Handler) CreateGroup(ctx *gin.Context) {
 // @Param        id       path     string     true  "Group ID"
 // @Param        Group    body     pb.Group   true  "Group"
 // @Success      200      {string} string     "Update Successful"
-// @Failure      401      {string} string     "Error while updating"
+// @Failure      401      {object} apierror.Response "Error while updating"
 // @Router       /group/update/{id} [put]
 func (h *Handler) UpdateGroup(ctx *gin.Context) {
 	var group pb.Group
-	if err := ctx.ShouldBindJSON(&group); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &group) {
 		return
 	}
 	group.Id = ctx.Param("id")
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.hasGroup(group.Id) {
+		outOfScope(ctx, "group")
+		return
+	}
+	if group.Department != nil && !sc.hasDepartment(group.Department.Id) {
+		outOfScope(ctx, "department")
+		return
+	}
 	_, err := h.GroupService.Update(ctx, &group)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Update Successful")
@@ -68,13 +89,21 @@ func (h *Handler) UpdateGroup(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id     path     string   true  "Group ID"
 // @Success      200    {string} string  "Delete Successful"
-// @Failure      401    {string} string  "Error while deleting"
+// @Failure      401    {object} apierror.Response "Error while deleting"
 // @Router       /group/delete/{id} [delete]
 func (h *Handler) DeleteGroup(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.hasGroup(id.Id) {
+		outOfScope(ctx, "group")
+		return
+	}
 	_, err := h.GroupService.Delete(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Delete Successful")
@@ -89,13 +118,21 @@ func (h *Handler) DeleteGroup(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id     path     string     true  "Group ID"
 // @Success      200    {object} pb.Group  "Get Successful"
-// @Failure      401    {string} string    "Error while getting"
+// @Failure      401    {object} apierror.Response "Error while getting"
 // @Router       /group/get/{id} [get]
 func (h *Handler) GetGroup(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.hasGroup(id.Id) {
+		outOfScope(ctx, "group")
+		return
+	}
 	res, err := h.GroupService.Get(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -109,16 +146,30 @@ func (h *Handler) GetGroup(ctx *gin.Context) {
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        query  query    pb.GetAllDepartmentFilter  true  "Query parameter"
-// @Success      200    {object} pb.AllGroups "Get All Successful"
-// @Failure      401    {string} string       "Error while getting all"
+// @Param        limit   query    int     false  "Page size, 1 to 100"
+// @Param        offset  query    int     false  "Number of items to skip"
+// @Param        cursor  query    string  false  "next_cursor of the previous page"
+// @Param        sort    query    string  false  "Comma-separated JSON fields, - for descending, e.g. name,-quantity"
+// @Success      200    {object} GroupPage "Get All Successful"
+// @Failure      400    {object} apierror.Problem "Invalid pagination parameter"
+// @Failure      401    {object} apierror.Response "Error while getting all"
 // @Router       /group/getall [get]
 func (h *Handler) GetAllGroups(ctx *gin.Context) {
 	name := ctx.Query("name")
 	req := pb.GroupReq{Name: name}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
 	res, err := h.GroupService.GetAll(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, res)
+	res.Groups = sc.filterGroups(res.Groups)
+	page, meta, ok := pagination.Paginate(ctx, res.Groups)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, GroupPage{Groups: page, Meta: meta})
 }
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"github.com/Salikhov079/military/api/audit"
+	"github.com/Salikhov079/military/api/middleware"
+	"github.com/Salikhov079/military/api/saga"
+	"github.com/Salikhov079/military/api/stream"
+	"github.com/Salikhov079/military/api/thread"
+	t "github.com/Salikhov079/military/api/token"
 	pb "github.com/Salikhov079/military/genprotos/militaries"
 	pbs "github.com/Salikhov079/military/genprotos/soldiers"
 	ai  "github.com/Salikhov079/military/genprotos/ai"
@@ -16,12 +22,20 @@ type Handler struct {
 	GroupService pbs.GroupServiceClient
 	SoldierService pbs.SoldierServiceClient
 	Ai  ai.AiServiceClient
+	Credentials t.CredentialStore
+	Audit       *audit.Log
+	Sagas       *saga.Coordinator
+	Streams     *stream.Hub
+	Threads     thread.Store
+	Policy      *middleware.Policy
 
 
 }
 
 func NewHandler(bu pb.BulletServiceClient, fu pb.FuelServiceClient,
 	te pb.TechniqueServiceClient, co pbs.CommanderServiceClient, de pbs.DepartmentServiceClient, 
-	ge pbs.GroupServiceClient, so pbs.SoldierServiceClient, ai ai.AiServiceClient) *Handler {
-	return &Handler{bu, fu, te, co, de, ge, so, ai}
+	ge pbs.GroupServiceClient, so pbs.SoldierServiceClient, ai ai.AiServiceClient, cr t.CredentialStore, au *audit.Log, sg *saga.Coordinator, st *stream.Hub, th thread.Store, po *middleware.Policy) *Handler {
+	h := &Handler{bu, fu, te, co, de, ge, so, ai, cr, au, sg, st, th, po}
+	h.registerSagas()
+	return h
 }
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/thread"
+	ai "github.com/Salikhov079/military/genprotos/ai"
+	mil "github.com/Salikhov079/military/genprotos/militaries"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+)
+
+// The page types are the getall responses: the backend list under its usual
+// key plus the pagination fields.
+
+type BulletPage struct {
+	Bullets []*mil.Bullet `json:"bullets"`
+	pagination.Meta
+}
+
+type FuelPage struct {
+	Fuels []*mil.Fuel `json:"fuels"`
+	pagination.Meta
+}
+
+type TechniquePage struct {
+	Techniques []*mil.Technique `json:"techniques"`
+	pagination.Meta
+}
+
+type SoldierPage struct {
+	Soldiers []*pb.Soldier `json:"soldiers"`
+	pagination.Meta
+}
+
+type CommanderPage struct {
+	Commanders []*pb.Commander `json:"commanders"`
+	pagination.Meta
+}
+
+type DepartmentPage struct {
+	Departments []*pb.Department `json:"departments"`
+	pagination.Meta
+}
+
+type GroupPage struct {
+	Groups []*pb.Group `json:"groups"`
+	pagination.Meta
+}
+
+type ThreadPage struct {
+	Threads []*thread.Thread `json:"threads"`
+	pagination.Meta
+}
+
+type ThreadHistoryPage struct {
+	ThreadID string           `json:"thread_id"`
+	Requests []thread.Message `json:"requests"`
+	pagination.Meta
+}
+
+type HistoryPage struct {
+	Requests []*ai.GetAllAi `json:"requests"`
+	pagination.Meta
+}
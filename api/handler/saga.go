@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Salikhov079/military/api/saga"
+	"github.com/Salikhov079/military/genprotos/militaries"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+)
+
+const (
+	useBulletSaga = "usebullet"
+	useFuelSaga   = "usefuel"
+)
+
+// registerSagas makes the handler's sagas known to the coordinator so they
+// can be run and recovered.
+func (h *Handler) registerSagas() {
+	h.Sagas.Register(useBulletSaga, h.useBulletSteps)
+	h.Sagas.Register(useFuelSaga, h.useFuelSteps)
+}
+
+// useBulletSteps takes the bullets out of stock and then records their use
+// by the soldier.
+func (h *Handler) useBulletSteps(payload json.RawMessage) ([]saga.Step, error) {
+	var req pb.UseB
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+	return []saga.Step{
+		h.subBullet("military vehicle", req.QuantityBigWeapon),
+		h.subBullet("weapon", req.QuantityWeapon),
+		{
+			Name: "record bullet use",
+			Action: func(ctx context.Context) error {
+				_, err := h.SoldierService.UseBullet(ctx, &req)
+				return err
+			},
+		},
+	}, nil
+}
+
+// useFuelSteps takes the fuel out of stock and then records its use by the
+// soldier.
+func (h *Handler) useFuelSteps(payload json.RawMessage) ([]saga.Step, error) {
+	var req pb.UseF
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return nil, err
+	}
+	return []saga.Step{
+		h.subFuel("petrol", req.Petrol),
+		h.subFuel("diesel", req.Diesel),
+		{
+			Name: "record fuel use",
+			Action: func(ctx context.Context) error {
+				_, err := h.SoldierService.UseFuel(ctx, &req)
+				return err
+			},
+		},
+	}, nil
+}
+
+func (h *Handler) subBullet(name string, quantity int32) saga.Step {
+	req := &militaries.BulletAddSub{Name: name, Quantity: quantity}
+	return saga.Step{
+		Name: "subtract " + name + " bullets",
+		Action: func(ctx context.Context) error {
+			if quantity == 0 {
+				return nil
+			}
+			_, err := h.BulletService.Sub(ctx, req)
+			return err
+		},
+		Compensate: func(ctx context.Context) error {
+			if quantity == 0 {
+				return nil
+			}
+			_, err := h.BulletService.Add(ctx, req)
+			return err
+		},
+	}
+}
+
+func (h *Handler) subFuel(name string, quantity int32) saga.Step {
+	req := &militaries.FuelAddSub{Name: name, Quantity: quantity}
+	return saga.Step{
+		Name: "subtract " + name,
+		Action: func(ctx context.Context) error {
+			if quantity == 0 {
+				return nil
+			}
+			_, err := h.FuelService.Sub(ctx, req)
+			return err
+		},
+		Compensate: func(ctx context.Context) error {
+			if quantity == 0 {
+				return nil
+			}
+			_, err := h.FuelService.Add(ctx, req)
+			return err
+		},
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Salikhov079/military/api/apierror"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// scope is the set of departments and groups a commander may read and modify.
+// A nil scope means the caller is not restricted.
+type scope struct {
+	commanderID string
+	departments map[string]bool
+	groups      map[string]bool
+}
+
+// callerScope builds the caller's scope from the departments whose commander
+// is the JWT subject. Only commander tokens are scoped. On failure the error
+// response is already written and ok is false.
+func (h *Handler) callerScope(ctx *gin.Context) (s *scope, ok bool) {
+	s, err := h.scopeOf(ctx)
+	if err != nil {
+		apierror.AbortWithError(ctx, err)
+		return nil, false
+	}
+	return s, true
+}
+
+func (h *Handler) scopeOf(ctx *gin.Context) (*scope, error) {
+	if ctx.GetString("role") != "commander" {
+		return nil, nil
+	}
+
+	s := &scope{
+		commanderID: ctx.GetString("user_id"),
+		departments: map[string]bool{},
+		groups:      map[string]bool{},
+	}
+
+	deps, err := h.DepartmentService.GetAll(ctx, &pb.Department{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deps.Departments {
+		if d.Commander != nil && d.Commander.Id == s.commanderID {
+			s.departments[d.Id] = true
+		}
+	}
+
+	groups, err := h.GroupService.GetAll(ctx, &pb.GroupReq{})
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups.Groups {
+		if g.Department != nil && s.departments[g.Department.Id] {
+			s.groups[g.Id] = true
+		}
+	}
+	return s, nil
+}
+
+func (s *scope) hasDepartment(id string) bool {
+	return s == nil || s.departments[id]
+}
+
+func (s *scope) hasGroup(id string) bool {
+	return s == nil || s.groups[id]
+}
+
+func (s *scope) hasSoldier(soldier *pb.Soldier) bool {
+	if s == nil {
+		return true
+	}
+	return soldier.Group != nil && s.groups[soldier.Group.Id]
+}
+
+// ownsDepartment reports whether the department may be assigned to the given
+// commander by the caller.
+func (s *scope) ownsDepartment(dept *pb.Department) bool {
+	if s == nil {
+		return true
+	}
+	return dept.Commander != nil && dept.Commander.Id == s.commanderID
+}
+
+func (s *scope) filterSoldiers(all []*pb.Soldier) []*pb.Soldier {
+	if s == nil {
+		return all
+	}
+	res := make([]*pb.Soldier, 0, len(all))
+	for _, soldier := range all {
+		if s.hasSoldier(soldier) {
+			res = append(res, soldier)
+		}
+	}
+	return res
+}
+
+func (s *scope) filterGroups(all []*pb.Group) []*pb.Group {
+	if s == nil {
+		return all
+	}
+	res := make([]*pb.Group, 0, len(all))
+	for _, g := range all {
+		if s.groups[g.Id] {
+			res = append(res, g)
+		}
+	}
+	return res
+}
+
+func (s *scope) filterDepartments(all []*pb.Department) []*pb.Department {
+	if s == nil {
+		return all
+	}
+	res := make([]*pb.Department, 0, len(all))
+	for _, d := range all {
+		if s.departments[d.Id] {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+func outOfScope(ctx *gin.Context, entity string) {
+	apierror.Abort(ctx, codes.PermissionDenied, entity+" is outside of your department")
+}
+
+// soldierInScope loads the soldier and checks it against the caller's scope,
+// writing the error response when it is not accessible.
+func (h *Handler) soldierInScope(ctx *gin.Context, s *scope, id string) bool {
+	if s == nil {
+		return true
+	}
+	soldier, err := h.SoldierService.Get(ctx, &pb.ById{Id: id})
+	if err != nil {
+		apierror.AbortWithError(ctx, err)
+		return false
+	}
+	if !s.hasSoldier(soldier) {
+		outOfScope(ctx, "soldier")
+		return false
+	}
+	return true
+}
+
+// usedInScope drops the usage records of soldiers outside of sc.
+func usedInScope[T interface{ GetSoldierId() string }](ctx context.Context, h *Handler, sc *scope, used []T) ([]T, error) {
+	if sc == nil {
+		return used, nil
+	}
+	soldiers, err := h.SoldierService.GetAll(ctx, &pb.SoldierReq{})
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for _, s := range sc.filterSoldiers(soldiers.Soldiers) {
+		ids[s.Id] = true
+	}
+	var res []T
+	for _, u := range used {
+		if ids[u.GetSoldierId()] {
+			res = append(res, u)
+		}
+	}
+	return res, nil
+}
@@ -1,13 +1,17 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/metrics"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/validation"
 	"github.com/Salikhov079/military/genprotos/militaries"
 	pb "github.com/Salikhov079/military/genprotos/soldiers"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
 )
 
 // CreateSoldier handles the creation of a new Soldier
@@ -19,17 +23,24 @@ import (
 // @Security  		BearerAuth
 // @Param        SoldierReq  body     pb.CreateSoldier  true  "Soldier Request"
 // @Success      200         {string} string         "Create Successful"
-// @Failure      401         {string} string         "Error while creating"
+// @Failure      401         {object} apierror.Response "Error while creating"
 // @Router       /soldier/create [post]
 func (h *Handler) CreateSoldier(ctx *gin.Context) {
 	var req pb.SoldierReq
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if !sc.hasGroup(req.GroupId) {
+		outOfScope(ctx, "group")
 		return
 	}
 	_, err := h.SoldierService.Create(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Create Successful")
@@ -45,18 +56,25 @@ func (h *Handler) CreateSoldier(ctx *gin.Context) {
 // @Param        id       path     string     true  "Soldier ID"
 // @Param        Soldier  body     pb.Soldier true  "Soldier"
 // @Success      200      {string} string     "Update Successful"
-// @Failure      401      {string} string     "Error while updating"
+// @Failure      401      {object} apierror.Response "Error while updating"
 // @Router       /soldier/update/{id} [put]
 func (h *Handler) UpdateSoldier(ctx *gin.Context) {
 	var soldier pb.Soldier
-	if err := ctx.ShouldBindJSON(&soldier); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &soldier) {
 		return
 	}
 	soldier.Id = ctx.Param("id")
+	sc, ok := h.callerScope(ctx)
+	if !ok || !h.soldierInScope(ctx, sc, soldier.Id) {
+		return
+	}
+	if soldier.Group != nil && !sc.hasGroup(soldier.Group.Id) {
+		outOfScope(ctx, "group")
+		return
+	}
 	_, err := h.SoldierService.Update(ctx, &soldier)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Update Successful")
@@ -71,13 +89,17 @@ func (h *Handler) UpdateSoldier(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id     path     string   true  "Soldier ID"
 // @Success      200    {string} string  "Delete Successful"
-// @Failure      401    {string} string  "Error while deleting"
+// @Failure      401    {object} apierror.Response "Error while deleting"
 // @Router       /soldier/delete/{id} [delete]
 func (h *Handler) DeleteSoldier(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
+	sc, ok := h.callerScope(ctx)
+	if !ok || !h.soldierInScope(ctx, sc, id.Id) {
+		return
+	}
 	_, err := h.SoldierService.Delete(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Delete Successful")
@@ -92,13 +114,21 @@ func (h *Handler) DeleteSoldier(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id     path     string     true  "Soldier ID"
 // @Success      200    {object} pb.Soldier "Get Successful"
-// @Failure      401    {string} string     "Error while getting"
+// @Failure      401    {object} apierror.Response "Error while getting"
 // @Router       /soldier/get/{id} [get]
 func (h *Handler) GetSoldier(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
 	res, err := h.SoldierService.Get(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
+		return
+	}
+	if !sc.hasSoldier(res) {
+		outOfScope(ctx, "soldier")
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -112,8 +142,13 @@ func (h *Handler) GetSoldier(ctx *gin.Context) {
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        query  query    pb.GetAllSoldierFilter  true  "Query parameter"
-// @Success      200    {object} pb.AllSoldiers "Get All Successful"
-// @Failure      401    {string} string         "Error while getting all"
+// @Param        limit   query    int     false  "Page size, 1 to 100"
+// @Param        offset  query    int     false  "Number of items to skip"
+// @Param        cursor  query    string  false  "next_cursor of the previous page"
+// @Param        sort    query    string  false  "Comma-separated JSON fields, - for descending, e.g. name,-quantity"
+// @Success      200    {object} SoldierPage "Get All Successful"
+// @Failure      400    {object} apierror.Problem "Invalid pagination parameter"
+// @Failure      401    {object} apierror.Response "Error while getting all"
 // @Router       /soldier/getall [get]
 func (h *Handler) GetAllSoldiers(ctx *gin.Context) {
 	age := ctx.Query("age")
@@ -121,15 +156,24 @@ func (h *Handler) GetAllSoldiers(ctx *gin.Context) {
 	name := ctx.Query("name")
 	req := pb.SoldierReq{Email: email, Name: name, DateOfBirth: age}
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(ctx, codes.InvalidArgument, err.Error())
+		return
+	}
+	sc, ok := h.callerScope(ctx)
+	if !ok {
 		return
 	}
 	res, err := h.SoldierService.GetAll(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, res)
+	res.Soldiers = sc.filterSoldiers(res.Soldiers)
+	page, meta, ok := pagination.Paginate(ctx, res.Soldiers)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, SoldierPage{Soldiers: page, Meta: meta})
 }
 
 // UseBullet handles the use of bullets by a soldier
@@ -141,36 +185,39 @@ func (h *Handler) GetAllSoldiers(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        UseB  body     pb.UseB  true  "Use Bullet"
 // @Success      200   {string} string   "Use Bullet Successful"
-// @Failure      401   {string} string   "Error while using bullet"
+// @Failure      401   {object} apierror.Response "Error while using bullet"
 // @Router       /soldier/usebullet [post]
 func (h *Handler) UseBullet(ctx *gin.Context) {
 	var req pb.UseB
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	sc, ok := h.callerScope(ctx)
+	if !ok || !h.soldierInScope(ctx, sc, req.SoldierId) {
 		return
 	}
 	res, err := h.BulletService.GetAll(ctx, &militaries.BulletReq{})
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	for i := 0; i < len(res.Bullets); i++ {
 		if res.Bullets[i].Type == "weapon" && res.Bullets[i].Quantity < req.QuantityWeapon {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": errors.New("weapon is not enaught")})
+			apierror.Abort(ctx, codes.FailedPrecondition, "weapon is not enough")
 			return
 		}
 		if res.Bullets[i].Type == "military vehicle" && res.Bullets[i].Quantity < req.QuantityBigWeapon {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": errors.New("big weapon is not enaught")})
+			apierror.Abort(ctx, codes.FailedPrecondition, "big weapon is not enough")
 			return
 		}
 	}
-	h.BulletService.Sub(ctx, &militaries.BulletAddSub{Name: "military vehicle", Quantity: req.QuantityBigWeapon})
-	h.BulletService.Sub(ctx, &militaries.BulletAddSub{Name: "weapon", Quantity: req.QuantityWeapon})
-	_, err = h.SoldierService.UseBullet(ctx, &req)
+	err = h.Sagas.Run(ctx, useBulletSaga, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
+	metrics.BulletsSubtracted("weapon", metrics.SourceUse, req.QuantityWeapon)
+	metrics.BulletsSubtracted("military vehicle", metrics.SourceUse, req.QuantityBigWeapon)
 	ctx.JSON(http.StatusOK, "Use Bullet Successful")
 }
 
@@ -183,36 +230,39 @@ func (h *Handler) UseBullet(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        UseF  body     pb.UseF  true  "Use Fuel"
 // @Success      200   {string} string   "Use Fuel Successful"
-// @Failure      401   {string} string   "Error while using fuel"
+// @Failure      401   {object} apierror.Response "Error while using fuel"
 // @Router       /soldier/usefuel [post]
 func (h *Handler) UseFuel(ctx *gin.Context) {
 	var req pb.UseF
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	sc, ok := h.callerScope(ctx)
+	if !ok || !h.soldierInScope(ctx, sc, req.SoldierId) {
 		return
 	}
 	res, err := h.FuelService.GetAll(ctx, &militaries.FuelReq{})
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	for i := 0; i < len(res.Fuels); i++ {
 		if res.Fuels[i].Type == "diesel" && res.Fuels[i].Quantity < req.Diesel {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": errors.New("diesel is not enaught")})
+			apierror.Abort(ctx, codes.FailedPrecondition, "diesel is not enough")
 			return
 		}
 		if res.Fuels[i].Type == "petrol" && res.Fuels[i].Quantity < req.Petrol {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": errors.New("petrol is not enaught")})
+			apierror.Abort(ctx, codes.FailedPrecondition, "petrol is not enough")
 			return
 		}
 	}
-	h.FuelService.Sub(ctx, &militaries.FuelAddSub{Name: "petrol", Quantity: req.Petrol})
-	h.FuelService.Sub(ctx, &militaries.FuelAddSub{Name: "diesel", Quantity: req.Diesel})
-	_, err = h.SoldierService.UseFuel(ctx, &req)
+	err = h.Sagas.Run(ctx, useFuelSaga, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
+	metrics.FuelConsumed("diesel", metrics.SourceUse, req.Diesel)
+	metrics.FuelConsumed("petrol", metrics.SourceUse, req.Petrol)
 
 	ctx.JSON(http.StatusOK, "Use Fuel Successful")
 }
@@ -227,7 +277,7 @@ func (h *Handler) UseFuel(ctx *gin.Context) {
 // @Param        join_date   query    string  false  "Join date of the soldier (format: YYYY-MM-DD)"
 // @Param        end_date    query    string  false  "End date of the soldier (format: YYYY-MM-DD)"
 // @Success      200         {object} pb.AllSoldiers "Get All Successful"
-// @Failure      401         {string} string         "Error while getting all"
+// @Failure      401         {object} apierror.Response "Error while getting all"
 // @Router       /soldier/dashbord [get]
 func (h *Handler) Dashbord(ctx *gin.Context) {
 	var req pb.SoldierReq
@@ -235,17 +285,22 @@ func (h *Handler) Dashbord(ctx *gin.Context) {
 	req.JoinDate = ctx.Query("join_date")
 	req.EndDate = ctx.Query("end_date")
 
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
 	res, err := h.SoldierService.GetAll(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
+	res.Soldiers = sc.filterSoldiers(res.Soldiers)
 	ctx.JSON(http.StatusOK, res)
 }
 
 // GetAllWeaponStatistik handles getting all weapon statistics
 // @Summary      Get All Weapon Statistics
-// @Description  Get all weapon statistics for soldiers
+// @Description  Get all weapon statistics for soldiers. Commanders only get the statistics of the soldiers of their departments.
 // @Tags         Dashbord
 // @Accept       json
 // @Produce      json
@@ -253,19 +308,27 @@ func (h *Handler) Dashbord(ctx *gin.Context) {
 // @Param        date        query    string  false  "Date in the format YYYY-MM-DD"
 // @Param        soldier_id  query    string  false  "Soldier ID"
 // @Success      200         {object} pb.GetSoldierStatistikRes "Get All Successful"
-// @Failure      400         {string} string                     "Invalid query parameter"
-// @Failure      401         {string} string                     "Unauthorized"
-// @Failure      500         {string} string                     "Internal server error"
+// @Failure      400         {object} apierror.Problem           "Invalid query parameter"
+// @Failure      401         {object} apierror.Response          "Unauthorized"
+// @Failure      403         {object} apierror.Response          "Soldier is outside of your department"
+// @Failure      500         {object} apierror.Response          "Internal server error"
 // @Router       /soldier/getallweaponstatistik [get]
 func (h *Handler) GetAllWeaponStatistik(ctx *gin.Context) {
-	date := ctx.Query("date")
-	if date == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "date parameter is required"})
+	date, ok := validation.QueryDate(ctx, "date")
+	if !ok {
 		return
 	}
 
 	soldierID := ctx.Query("soldier_id")
 
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if soldierID != "" && !h.soldierInScope(ctx, sc, soldierID) {
+		return
+	}
+
 	req := pb.GetSoldierStatistik{
 		Date:      date,
 		SoldierId: soldierID,
@@ -273,7 +336,11 @@ func (h *Handler) GetAllWeaponStatistik(ctx *gin.Context) {
 
 	res, err := h.SoldierService.StatistikWeapons(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
+		return
+	}
+	if res.UsedWeapons, err = usedInScope(ctx, h, sc, res.UsedWeapons); err != nil {
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -281,7 +348,7 @@ func (h *Handler) GetAllWeaponStatistik(ctx *gin.Context) {
 
 // GetAllFuelStatistik handles getting all fuel statistics
 // @Summary      Get All Fuel Statistics
-// @Description  Get all fuel statistics for soldiers
+// @Description  Get all fuel statistics for soldiers. Commanders only get the statistics of the soldiers of their departments.
 // @Tags         Dashbord
 // @Accept       json
 // @Produce      json
@@ -289,19 +356,27 @@ func (h *Handler) GetAllWeaponStatistik(ctx *gin.Context) {
 // @Param        date        query    string  false  "Date in the format YYYY-MM-DD"
 // @Param        soldier_id  query    string  false "Soldier ID"
 // @Success      200         {object} pb.GetSoldierStatistikFuelRes "Get All Successful"
-// @Failure      400         {string} string                         "Invalid query parameter"
-// @Failure      401         {string} string                         "Unauthorized"
-// @Failure      500         {string} string                         "Internal server error"
+// @Failure      400         {object} apierror.Problem               "Invalid query parameter"
+// @Failure      401         {object} apierror.Response              "Unauthorized"
+// @Failure      403         {object} apierror.Response              "Soldier is outside of your department"
+// @Failure      500         {object} apierror.Response              "Internal server error"
 // @Router       /soldier/getallfuelstatistik [get]
 func (h *Handler) GetAllFuelStatistik(ctx *gin.Context) {
-	date := ctx.Query("date")
-	if date == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "date parameter is required"})
+	date, ok := validation.QueryDate(ctx, "date")
+	if !ok {
 		return
 	}
 
 	soldierID := ctx.Query("soldier_id")
 
+	sc, ok := h.callerScope(ctx)
+	if !ok {
+		return
+	}
+	if soldierID != "" && !h.soldierInScope(ctx, sc, soldierID) {
+		return
+	}
+
 	req := pb.GetSoldierStatistikFuel{
 		Date:      date,
 		SoldierId: soldierID,
@@ -309,7 +384,11 @@ func (h *Handler) GetAllFuelStatistik(ctx *gin.Context) {
 
 	res, err := h.SoldierService.FuelStatistik(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
+		return
+	}
+	if res.UsedFuel, err = usedInScope(ctx, h, sc, res.UsedFuel); err != nil {
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
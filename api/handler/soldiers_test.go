@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+type fakeDepartments struct {
+	pb.DepartmentServiceClient
+}
+
+func (fakeDepartments) GetAll(context.Context, *pb.Department, ...grpc.CallOption) (*pb.AllDepartments, error) {
+	return &pb.AllDepartments{Departments: []*pb.Department{
+		{Id: "d1", Commander: &pb.Commander{Id: "c1"}},
+		{Id: "d2", Commander: &pb.Commander{Id: "c2"}},
+	}}, nil
+}
+
+type fakeGroups struct {
+	pb.GroupServiceClient
+}
+
+func (fakeGroups) GetAll(context.Context, *pb.GroupReq, ...grpc.CallOption) (*pb.AllGroups, error) {
+	return &pb.AllGroups{Groups: []*pb.Group{
+		{Id: "g1", Department: &pb.Department{Id: "d1"}},
+		{Id: "g2", Department: &pb.Department{Id: "d2"}},
+	}}, nil
+}
+
+func (f fakeSoldiers) Get(_ context.Context, req *pb.ById, _ ...grpc.CallOption) (*pb.Soldier, error) {
+	for _, s := range f.soldiers {
+		if s.Id == req.Id {
+			return s, nil
+		}
+	}
+	return &pb.Soldier{Id: req.Id}, nil
+}
+
+func (f fakeSoldiers) StatistikWeapons(context.Context, *pb.GetSoldierStatistik, ...grpc.CallOption) (*pb.GetSoldierStatistikRes, error) {
+	return &pb.GetSoldierStatistikRes{UsedWeapons: []*pb.UseB{{SoldierId: "s1"}, {SoldierId: "s2"}}}, nil
+}
+
+func TestGetAllWeaponStatistikScope(t *testing.T) {
+	h := &Handler{
+		DepartmentService: fakeDepartments{},
+		GroupService:      fakeGroups{},
+		SoldierService: fakeSoldiers{soldiers: []*pb.Soldier{
+			{Id: "s1", Group: &pb.Group{Id: "g1"}},
+			{Id: "s2", Group: &pb.Group{Id: "g2"}},
+		}},
+	}
+	tests := []struct {
+		role, query string
+		code        int
+		soldiers    int
+	}{
+		{"commander", "", http.StatusOK, 1},
+		{"commander", "&soldier_id=s1", http.StatusOK, 1},
+		{"commander", "&soldier_id=s2", http.StatusForbidden, 0},
+		{"quartermaster", "", http.StatusOK, 2},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Set("user_id", "c1")
+			ctx.Set("role", tt.role)
+		})
+		r.GET("/soldier/getallweaponstatistik", h.GetAllWeaponStatistik)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/soldier/getallweaponstatistik?date=2024-01-01"+tt.query, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s %s: status %d, want %d", tt.role, tt.query, w.Code, tt.code)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		var res pb.GetSoldierStatistikRes
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.UsedWeapons) != tt.soldiers {
+			t.Errorf("%s %s: %d records, want %d", tt.role, tt.query, len(res.UsedWeapons), tt.soldiers)
+		}
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/metrics"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/validation"
 	pb "github.com/Salikhov079/military/genprotos/militaries"
 
 	"github.com/gin-gonic/gin"
@@ -18,17 +22,16 @@ import (
 // @Security  		BearerAuth
 // @Param        TechniqueReq  body     pb.TechniqueReq  true  "Technique Request"
 // @Success      200           {string} string           "Create Successful"
-// @Failure      400           {string} string           "Error while creating"
+// @Failure      400           {object} apierror.Problem "Error while creating"
 // @Router       /technique/create [post]
 func (h *Handler) CreateTechnique(ctx *gin.Context) {
 	var req pb.TechniqueReq
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &req) {
 		return
 	}
 	_, err := h.TechniqueService.Create(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Create Successful")
@@ -44,18 +47,17 @@ func (h *Handler) CreateTechnique(ctx *gin.Context) {
 // @Param        id         path     string       true  "Technique ID"
 // @Param        Technique  body     pb.Technique true  "Technique"
 // @Success      200        {string} string       "Update Successful"
-// @Failure      400        {string} string       "Error while updating"
+// @Failure      400        {object} apierror.Problem "Error while updating"
 // @Router       /technique/update/{id} [put]
 func (h *Handler) UpdateTechnique(ctx *gin.Context) {
 	var technique pb.Technique
-	if err := ctx.ShouldBindJSON(&technique); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &technique) {
 		return
 	}
 	technique.Id = ctx.Param("id")
 	_, err := h.TechniqueService.Update(ctx, &technique)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Update Successful")
@@ -70,13 +72,13 @@ func (h *Handler) UpdateTechnique(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id    path     string    true  "Technique ID"
 // @Success      200   {string} string    "Delete Successful"
-// @Failure      400   {string} string    "Error while deleting"
+// @Failure      400   {object} apierror.Response "Error while deleting"
 // @Router       /technique/delete/{id} [delete]
 func (h *Handler) DeleteTechnique(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
 	_, err := h.TechniqueService.Delete(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Delete Successful")
@@ -91,13 +93,13 @@ func (h *Handler) DeleteTechnique(ctx *gin.Context) {
 // @Security  		BearerAuth
 // @Param        id    path     string       true  "Technique ID"
 // @Success      200   {object} pb.Technique "Get Successful"
-// @Failure      400   {string} string       "Error while getting"
+// @Failure      400   {object} apierror.Response "Error while getting"
 // @Router       /technique/getbyid/{id} [get]
 func (h *Handler) GetTechnique(ctx *gin.Context) {
 	id := pb.ById{Id: ctx.Param("id")}
 	res, err := h.TechniqueService.Get(ctx, &id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, res)
@@ -111,8 +113,13 @@ func (h *Handler) GetTechnique(ctx *gin.Context) {
 // @Produce      json
 // @Security  		BearerAuth
 // @Param        query  query    pb.TechniqueReq true  "Query parameter"
-// @Success      200    {object} pb.AllTechnique "Get All Successful"
-// @Failure      400    {string} string          "Error while getting all"
+// @Param        limit   query    int     false  "Page size, 1 to 100"
+// @Param        offset  query    int     false  "Number of items to skip"
+// @Param        cursor  query    string  false  "next_cursor of the previous page"
+// @Param        sort    query    string  false  "Comma-separated JSON fields, - for descending, e.g. name,-quantity"
+// @Success      200    {object} TechniquePage "Get All Successful"
+// @Failure      400    {object} apierror.Problem "Invalid pagination parameter"
+// @Failure      400    {object} apierror.Response "Error while getting all"
 // @Router       /technique/getall [get]
 func (h *Handler) GetAllTechniques(ctx *gin.Context) {
 	mo := ctx.Query("model")
@@ -122,10 +129,14 @@ func (h *Handler) GetAllTechniques(ctx *gin.Context) {
 	req := pb.TechniqueReq{Model: mo, Quantity: int32(q), Type: ty}
 	res, err := h.TechniqueService.GetAll(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, res)
+	page, meta, ok := pagination.Paginate(ctx, res.Techniques)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, TechniquePage{Techniques: page, Meta: meta})
 }
 
 // Add handles adding quantity to a technique
@@ -137,18 +148,17 @@ func (h *Handler) GetAllTechniques(ctx *gin.Context) {
 // @Security     BearerAuth
 // @Param        technique body pb.TechniqueAddSub true "Technique data"
 // @Success      200    {object} pb.Void "Add Successful"
-// @Failure      500    {string} string  "Error while adding quantity"
+// @Failure      500    {object} apierror.Response "Error while adding quantity"
 // @Router       /technique/add [put]
 func (h *Handler) AddTechnique(ctx *gin.Context) {
 	var technique pb.TechniqueAddSub
-	if err := ctx.ShouldBindJSON(&technique); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &technique) {
 		return
 	}
 
 	_, err := h.TechniqueService.Add(ctx, &technique)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, "Updated")
@@ -163,19 +173,19 @@ func (h *Handler) AddTechnique(ctx *gin.Context) {
 // @Security     BearerAuth
 // @Param        technique body pb.TechniqueAddSub true "Technique data"
 // @Success      200    {object} pb.Void "Subtract Successful"
-// @Failure      500    {string} string  "Error while subtracting quantity"
+// @Failure      500    {object} apierror.Response "Error while subtracting quantity"
 // @Router       /technique/sub [post]
 func (h *Handler) SubTechnique(ctx *gin.Context) {
 	var technique pb.TechniqueAddSub
-	if err := ctx.ShouldBindJSON(&technique); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(ctx, &technique) {
 		return
 	}
 
 	_, err := h.TechniqueService.Sub(ctx, &technique)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.AbortWithError(ctx, err)
 		return
 	}
+	metrics.TechniquesSubtracted(technique.Name, technique.Quantity)
 	ctx.JSON(http.StatusOK, "Updated")
 }
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/requestid"
+	"github.com/Salikhov079/military/api/thread"
+	"github.com/Salikhov079/military/api/validation"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// CrossUserRoute is the policy route that lets a role read and change the
+// AI threads and history of other users. It lies outside /ai/ so that
+// granting "* /ai/*" does not grant it.
+const CrossUserRoute = "/users/:user_id/ai"
+
+// ThreadReq is the body of creating or renaming a thread.
+type ThreadReq struct {
+	Title string `json:"title" binding:"required,max=200"`
+}
+
+// CreateThread handles creating a conversation thread
+// @Summary      Create Thread
+// @Description  Start a new AI conversation thread of the caller. Send its ID as thread_id with chat messages. A user may have up to ai_thread_max_threads threads besides the default one.
+// @Tags         AI
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Thread  body     ThreadReq  true  "Thread"
+// @Success      201     {object} thread.Thread "Create Successful"
+// @Failure      400     {object} apierror.Problem "Invalid request or thread limit reached"
+// @Router       /ai/threads [post]
+func (h *Handler) CreateThread(ctx *gin.Context) {
+	var req ThreadReq
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	t := &thread.Thread{Owner: ctx.GetString("user_id"), Title: req.Title}
+	if err := h.Threads.Create(t); err != nil {
+		abortThread(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, t)
+}
+
+// ListThreads handles listing conversation threads
+// @Summary      List Threads
+// @Description  Get the AI conversation threads of the caller, the most recently updated first. Roles the policy allows on /users/:user_id/ai may list the threads of another user.
+// @Tags         AI
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id  query    string  false  "User ID, roles the policy allows on /users/:user_id/ai only"
+// @Param        limit    query    int     false  "Page size, 1 to 100"
+// @Param        offset   query    int     false  "Number of items to skip"
+// @Param        cursor   query    string  false  "next_cursor of the previous page"
+// @Param        sort     query    string  false  "Comma-separated JSON fields, - for descending, e.g. title"
+// @Success      200      {object} ThreadPage "Get All Successful"
+// @Failure      400      {object} apierror.Problem "Invalid pagination parameter"
+// @Failure      403      {object} apierror.Response "Not allowed to read threads of another user"
+// @Router       /ai/threads [get]
+func (h *Handler) ListThreads(ctx *gin.Context) {
+	owner, ok := h.targetUser(ctx, ctx.Query("user_id"))
+	if !ok {
+		return
+	}
+	threads, err := h.Threads.List(owner)
+	if err != nil {
+		abortThread(ctx, err)
+		return
+	}
+	page, meta, ok := pagination.Paginate(ctx, threads)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, ThreadPage{Threads: page, Meta: meta})
+}
+
+// RenameThread handles renaming a conversation thread
+// @Summary      Rename Thread
+// @Description  Change the title of an AI conversation thread of the caller. Roles the policy allows on /users/:user_id/ai may rename a thread of another user.
+// @Tags         AI
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path     string     true   "Thread ID"
+// @Param        user_id  query    string     false  "User ID, roles the policy allows on /users/:user_id/ai only"
+// @Param        Thread   body     ThreadReq  true   "Thread"
+// @Success      200      {object} thread.Thread "Update Successful"
+// @Failure      400      {object} apierror.Problem "Invalid request"
+// @Failure      403      {object} apierror.Response "Not allowed to change threads of another user"
+// @Failure      404      {object} apierror.Response "Thread not found"
+// @Router       /ai/threads/{id} [put]
+func (h *Handler) RenameThread(ctx *gin.Context) {
+	owner, ok := h.targetUser(ctx, ctx.Query("user_id"))
+	if !ok {
+		return
+	}
+	var req ThreadReq
+	if !validation.BindJSON(ctx, &req) {
+		return
+	}
+	t, err := h.Threads.Rename(owner, ctx.Param("id"), req.Title)
+	if err != nil {
+		abortThread(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, t)
+}
+
+// DeleteThread handles deleting a conversation thread
+// @Summary      Delete Thread
+// @Description  Delete an AI conversation thread of the caller and its messages. The default thread is created again by the next message without a thread. Roles the policy allows on /users/:user_id/ai may delete a thread of another user.
+// @Tags         AI
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path     string  true   "Thread ID"
+// @Param        user_id  query    string  false  "User ID, roles the policy allows on /users/:user_id/ai only"
+// @Success      200      {string} string  "Delete Successful"
+// @Failure      403      {object} apierror.Response "Not allowed to change threads of another user"
+// @Failure      404      {object} apierror.Response "Thread not found"
+// @Router       /ai/threads/{id} [delete]
+func (h *Handler) DeleteThread(ctx *gin.Context) {
+	owner, ok := h.targetUser(ctx, ctx.Query("user_id"))
+	if !ok {
+		return
+	}
+	if err := h.Threads.Delete(owner, ctx.Param("id")); err != nil {
+		abortThread(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, "Delete Successful")
+}
+
+// GetThreadHistory handles reading the messages of a conversation thread
+// @Summary      Thread History
+// @Description  Get the messages of an AI conversation thread of the caller, oldest first, optionally limited to a time range. Roles the policy allows on /users/:user_id/ai may read the history of another user.
+// @Tags         AI
+// @Produce      json
+// @Security     BearerAuth
+// @Param        thread_id  query    string  false  "Thread ID, default when empty"
+// @Param        from       query    string  false  "Start time (RFC 3339)"
+// @Param        to         query    string  false  "End time (RFC 3339)"
+// @Param        user_id    query    string  false  "User ID, roles the policy allows on /users/:user_id/ai only"
+// @Param        limit      query    int     false  "Page size, 1 to 100"
+// @Param        offset     query    int     false  "Number of items to skip"
+// @Param        cursor     query    string  false  "next_cursor of the previous page"
+// @Param        sort       query    string  false  "Comma-separated JSON fields, - for descending, e.g. request_text"
+// @Success      200        {object} ThreadHistoryPage "Get Successful"
+// @Failure      400        {object} apierror.Problem "Invalid query parameter"
+// @Failure      403        {object} apierror.Response "Not allowed to read the history of another user"
+// @Failure      404        {object} apierror.Response "Thread not found"
+// @Router       /ai/history [get]
+func (h *Handler) GetThreadHistory(ctx *gin.Context) {
+	owner, ok := h.targetUser(ctx, ctx.Query("user_id"))
+	if !ok {
+		return
+	}
+	from, ok := queryTime(ctx, "from")
+	if !ok {
+		return
+	}
+	to, ok := queryTime(ctx, "to")
+	if !ok {
+		return
+	}
+	id := ctx.DefaultQuery("thread_id", thread.Default)
+	messages, err := h.Threads.Messages(owner, id, from, to)
+	if err != nil {
+		abortThread(ctx, err)
+		return
+	}
+	page, meta, ok := pagination.Paginate(ctx, messages)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, ThreadHistoryPage{ThreadID: id, Requests: page, Meta: meta})
+}
+
+// targetUser returns the user a request acts on: the JWT subject, or the
+// requested user when the policy allows the caller's role the request's
+// method on CrossUserRoute. Other callers naming another user get 403 and ok
+// is false.
+func (h *Handler) targetUser(ctx *gin.Context, requested string) (string, bool) {
+	subject := ctx.GetString("user_id")
+	if requested == "" || requested == subject {
+		return subject, true
+	}
+	if !h.Policy.Allow(ctx.GetString("role"), ctx.Request.Method, CrossUserRoute) {
+		apierror.Abort(ctx, codes.PermissionDenied, "not allowed to access another user's AI history")
+		return "", false
+	}
+	return requested, true
+}
+
+// chatThread returns the thread of the caller named by the thread_id query
+// parameter, creating the default thread on first use. On failure the error
+// response is already written and ok is false.
+func (h *Handler) chatThread(ctx *gin.Context) (*thread.Thread, bool) {
+	owner := ctx.GetString("user_id")
+	id := ctx.DefaultQuery("thread_id", thread.Default)
+	t, err := h.Threads.Get(owner, id)
+	if errors.Is(err, thread.ErrNotFound) && id == thread.Default {
+		t = &thread.Thread{ID: id, Owner: owner, Title: "Default"}
+		if err = h.Threads.Create(t); errors.Is(err, thread.ErrExists) {
+			t, err = h.Threads.Get(owner, id)
+		}
+	}
+	if err != nil {
+		abortThread(ctx, err)
+		return nil, false
+	}
+	return t, true
+}
+
+// record adds a question and its answer to a thread. A failure only loses
+// the message from the gateway's history, so it is logged.
+func (h *Handler) record(ctx context.Context, t *thread.Thread, text, reply string) {
+	m := thread.Message{Time: time.Now().UTC(), RequestText: text, ResponseText: reply}
+	if err := h.Threads.Append(t.Owner, t.ID, m); err != nil {
+		slog.WarnContext(ctx, "Error while recording AI chat message", "request_id", requestid.FromContext(ctx), "thread_id", t.ID, "error", err)
+	}
+}
+
+func abortThread(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, thread.ErrNotFound):
+		apierror.Abort(ctx, codes.NotFound, err.Error())
+	case errors.Is(err, thread.ErrExists):
+		apierror.Abort(ctx, codes.AlreadyExists, err.Error())
+	case errors.Is(err, thread.ErrLimit):
+		apierror.Abort(ctx, codes.FailedPrecondition, err.Error())
+	default:
+		apierror.AbortWithError(ctx, err)
+	}
+}
+
+// queryTime returns the optional RFC 3339 query parameter name. On failure
+// it writes an application/problem+json response and returns false.
+func queryTime(ctx *gin.Context, name string) (time.Time, bool) {
+	v := ctx.Query(name)
+	if v == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		apierror.AbortWithValidation(ctx, "", []apierror.InvalidParam{{Name: name, Reason: "must be an RFC 3339 time"}})
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Salikhov079/military/api/middleware"
+	"github.com/Salikhov079/military/api/thread"
+	ai "github.com/Salikhov079/military/genprotos/ai"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+const testPolicy = `
+roles:
+  admin:
+    - "* /users/:user_id/ai"
+  soldier:
+    - "* /ai/*"
+`
+
+// historyRouter serves the AI history endpoints to a caller with the given
+// ID and role.
+func historyRouter(t *testing.T, user, role string) (*gin.Engine, *Handler) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := middleware.LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{Threads: thread.NewMemoryStore(thread.Limits{}), Policy: policy}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("user_id", user)
+		ctx.Set("role", role)
+	})
+	r.GET("/ai/history", h.GetThreadHistory)
+	r.GET("/ai/gethistory/:id", h.GetHistory)
+	return r, h
+}
+
+func TestTargetUser(t *testing.T) {
+	tests := []struct {
+		role, query string
+		want        int
+	}{
+		{"soldier", "", http.StatusOK},
+		{"soldier", "?user_id=u1", http.StatusOK},
+		{"soldier", "?user_id=u2", http.StatusForbidden},
+		{"admin", "?user_id=u2", http.StatusOK},
+	}
+	for _, tt := range tests {
+		r, h := historyRouter(t, "u1", tt.role)
+		for _, owner := range []string{"u1", "u2"} {
+			if err := h.Threads.Create(&thread.Thread{ID: thread.Default, Owner: owner}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/ai/history"+tt.query, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d", tt.role, tt.query, w.Code, tt.want)
+		}
+	}
+}
+
+// fakeAI serves a fixed history for every user and records who asked.
+type fakeAI struct {
+	ai.AiServiceClient
+	asked *string
+}
+
+func (f fakeAI) GetHistory(_ context.Context, req *ai.GetHistoryRequest, _ ...grpc.CallOption) (*ai.GetHistoryResponse, error) {
+	*f.asked = req.Id
+	return &ai.GetHistoryResponse{Requests: []*ai.GetAllAi{
+		{RequestText: "old", Time: "2024-01-01T10:00:00Z"},
+		{RequestText: "untimed"},
+		{RequestText: "new", Time: "2024-01-02T10:00:00Z"},
+	}}, nil
+}
+
+func TestGetHistory(t *testing.T) {
+	var asked string
+	r, h := historyRouter(t, "u1", "soldier")
+	h.Ai = fakeAI{asked: &asked}
+	tests := []struct {
+		path string
+		code int
+		want []string
+	}{
+		{"/ai/gethistory/u1", http.StatusOK, []string{"old", "untimed", "new"}},
+		{"/ai/gethistory/u1?from=2024-01-02T00:00:00Z", http.StatusOK, []string{"new"}},
+		{"/ai/gethistory/u1?to=2024-01-02T00:00:00Z", http.StatusOK, []string{"old"}},
+		{"/ai/gethistory/u1?from=yesterday", http.StatusBadRequest, nil},
+		{"/ai/gethistory/u2", http.StatusForbidden, nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s: status %d, want %d", tt.path, w.Code, tt.code)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		if asked != "u1" {
+			t.Errorf("%s: asked the AI service for %q, want u1", tt.path, asked)
+		}
+		var page HistoryPage
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, m := range page.Requests {
+			got = append(got, m.RequestText)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: requests = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Status values used in reports.
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFailing  = "unavailable"
+)
+
+// Dependency is a backend the gateway needs to serve requests.
+type Dependency struct {
+	Name string
+	Conn *grpc.ClientConn
+}
+
+// Check is the result of probing one dependency. The reports are public, so
+// the error of a failed probe is only logged.
+type Check struct {
+	Status    string `json:"status"`
+	State     string `json:"state"`
+	Health    string `json:"health,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// Report is the body of /healthz and /readyz.
+type Report struct {
+	Status string           `json:"status"`
+	Time   time.Time        `json:"time"`
+	Checks map[string]Check `json:"checks,omitempty"`
+}
+
+// Checker probes the backend connections.
+type Checker struct {
+	deps    []Dependency
+	timeout time.Duration
+}
+
+// New returns a checker that gives each probe at most timeout.
+func New(timeout time.Duration, deps ...Dependency) *Checker {
+	return &Checker{deps: deps, timeout: timeout}
+}
+
+// Livez reports whether the gateway process is running
+// @Summary      Liveness
+// @Description  Always succeeds while the process can serve HTTP; backends are not probed
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object} Report "Alive"
+// @Router       /livez [get]
+func (c *Checker) Livez(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Report{Status: StatusOK, Time: time.Now().UTC()})
+}
+
+// Readyz reports whether every backend is reachable
+// @Summary      Readiness
+// @Description  Probes the connection state and grpc.health.v1 service of every backend. Returns 503 when any backend is down
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object} Report "Ready"
+// @Failure      503  {object} Report "Not ready"
+// @Router       /readyz [get]
+func (c *Checker) Readyz(ctx *gin.Context) {
+	report := c.Probe(ctx)
+	code := http.StatusOK
+	if report.Status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	ctx.JSON(code, report)
+}
+
+// Healthz reports the health of the gateway and every backend
+// @Summary      Health
+// @Description  Same report as /readyz, but only fails with 503 when every backend is down
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object} Report "Healthy or degraded"
+// @Failure      503  {object} Report "Unavailable"
+// @Router       /healthz [get]
+func (c *Checker) Healthz(ctx *gin.Context) {
+	report := c.Probe(ctx)
+	code := http.StatusOK
+	if report.Status == StatusFailing {
+		code = http.StatusServiceUnavailable
+	}
+	ctx.JSON(code, report)
+}
+
+// Probe checks all dependencies concurrently. The report is ok when all are
+// up, degraded when some are down and unavailable when all are down.
+func (c *Checker) Probe(ctx context.Context) Report {
+	report := Report{Time: time.Now().UTC(), Checks: make(map[string]Check, len(c.deps))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, d := range c.deps {
+		wg.Add(1)
+		go func(d Dependency) {
+			defer wg.Done()
+			check, err := c.probe(ctx, d.Conn)
+			if err != nil {
+				slog.WarnContext(ctx, "Backend is down", "dependency", d.Name, "state", check.State, "error", err)
+			}
+			mu.Lock()
+			report.Checks[d.Name] = check
+			mu.Unlock()
+		}(d)
+	}
+	wg.Wait()
+
+	up := 0
+	for _, check := range report.Checks {
+		if check.Status == StatusUp {
+			up++
+		}
+	}
+	switch {
+	case up == len(report.Checks):
+		report.Status = StatusOK
+	case up == 0:
+		report.Status = StatusFailing
+	default:
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+// probe waits for conn to leave the idle and connecting states, then asks
+// the backend's grpc.health.v1 service for its overall status. Backends that
+// do not implement the health service are judged by connection state alone.
+// The error tells why a backend is down.
+func (c *Checker) probe(ctx context.Context, conn *grpc.ClientConn) (Check, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	start := time.Now()
+
+	state := conn.GetState()
+	for state == connectivity.Idle || state == connectivity.Connecting {
+		conn.Connect()
+		if !conn.WaitForStateChange(ctx, state) {
+			break
+		}
+		state = conn.GetState()
+	}
+	check := Check{Status: StatusDown, State: state.String()}
+	if state != connectivity.Ready {
+		check.LatencyMS = time.Since(start).Milliseconds()
+		return check, errors.New("connection is " + state.String())
+	}
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	check.LatencyMS = time.Since(start).Milliseconds()
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		check.Status = StatusUp
+	case err != nil:
+		return check, err
+	default:
+		check.Health = res.GetStatus().String()
+		if res.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+			check.Status = StatusUp
+		} else {
+			return check, errors.New("backend reports " + check.Health)
+		}
+	}
+	return check, nil
+}
@@ -0,0 +1,35 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestReadyzHidesErrors(t *testing.T) {
+	conn, err := grpc.NewClient("127.0.0.1:1", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := New(500*time.Millisecond, Dependency{Name: "soldiers", Conn: conn})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/readyz", c.Readyz)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	if body := w.Body.String(); strings.Contains(body, "127.0.0.1") || strings.Contains(body, "error") {
+		t.Errorf("report exposes backend details: %s", body)
+	}
+}
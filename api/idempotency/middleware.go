@@ -0,0 +1,136 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	header         = "Idempotency-Key"
+	replayedHeader = "Idempotent-Replayed"
+	maxKeyLength   = 255
+)
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Middleware makes POST, PUT and DELETE requests that carry an
+// Idempotency-Key header safe to retry. The first response for a key and
+// caller is stored for ttl and replayed for every duplicate. Reusing a key
+// with a different request is rejected with 422, and a duplicate arriving
+// while the first request is still running gets 409. The /auth routes are
+// never remembered: their responses are credentials, and a replayed refresh
+// would hand out a pair that was already rotated.
+func Middleware(store Store, ttl time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		method := ctx.Request.Method
+		key := ctx.GetHeader(header)
+		if key == "" || (method != http.MethodPost && method != http.MethodPut && method != http.MethodDelete) ||
+			strings.HasPrefix(ctx.Request.URL.Path, "/auth/") {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			apierror.Abort(ctx, codes.InvalidArgument, "Idempotency-Key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			apierror.Abort(ctx, codes.InvalidArgument, err.Error())
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.New()
+		sum.Write([]byte(method + " " + ctx.Request.URL.RequestURI() + "\n"))
+		sum.Write(body)
+		entry := &Entry{
+			Key:      ctx.GetString("user_id") + "\x00" + key,
+			BodyHash: hex.EncodeToString(sum.Sum(nil)),
+			Expires:  time.Now().Add(ttl),
+		}
+
+		existing, reserved, err := store.Reserve(entry)
+		if errors.Is(err, ErrFull) {
+			apierror.Abort(ctx, codes.ResourceExhausted, err.Error())
+			return
+		} else if err != nil {
+			apierror.Abort(ctx, codes.Internal, err.Error())
+			return
+		}
+		if !reserved {
+			replay(ctx, entry, existing)
+			return
+		}
+
+		// Server errors and panics are not remembered so that the client can
+		// retry; the reservation is dropped on the way out of either.
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := store.Release(entry.Key); err != nil {
+				slog.ErrorContext(ctx, "Error while releasing idempotency key", "request_id", ctx.GetString("request_id"), "error", err)
+			}
+		}()
+
+		w := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = w
+		ctx.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		entry.Done = true
+		entry.Status = w.Status()
+		entry.Header = http.Header{"Content-Type": w.Header().Values("Content-Type")}
+		entry.Body = w.body.Bytes()
+		if err := store.Save(entry); err != nil {
+			slog.ErrorContext(ctx, "Error while saving idempotency key", "request_id", ctx.GetString("request_id"), "error", err)
+			return
+		}
+		saved = true
+	}
+}
+
+func replay(ctx *gin.Context, entry, existing *Entry) {
+	switch {
+	case existing.BodyHash != entry.BodyHash:
+		apierror.AbortWithStatus(ctx, http.StatusUnprocessableEntity, "idempotency_key_reused",
+			"Idempotency-Key was already used for a different request")
+	case !existing.Done:
+		apierror.AbortWithStatus(ctx, http.StatusConflict, "idempotency_key_in_use",
+			"a request with this Idempotency-Key is still being processed")
+	default:
+		for name, values := range existing.Header {
+			for _, v := range values {
+				ctx.Writer.Header().Add(name, v)
+			}
+		}
+		ctx.Header(replayedHeader, "true")
+		ctx.Writer.WriteHeader(existing.Status)
+		ctx.Writer.Write(existing.Body)
+		ctx.Abort()
+	}
+}
@@ -0,0 +1,79 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		defer func() {
+			if recover() != nil {
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		ctx.Next()
+	})
+	r.Use(Middleware(NewMemoryStore(0), time.Hour))
+
+	calls := map[string]int{}
+	r.POST("/bullet/create", func(ctx *gin.Context) {
+		calls["create"]++
+		ctx.JSON(http.StatusOK, gin.H{"call": calls["create"]})
+	})
+	r.POST("/auth/refresh", func(ctx *gin.Context) {
+		calls["refresh"]++
+		ctx.JSON(http.StatusOK, gin.H{"call": calls["refresh"]})
+	})
+	r.POST("/fuel/create", func(ctx *gin.Context) {
+		calls["panic"]++
+		if calls["panic"] == 1 {
+			panic("boom")
+		}
+		ctx.Status(http.StatusOK)
+	})
+	r.POST("/technique/create", func(ctx *gin.Context) {
+		calls["fail"]++
+		ctx.Status(http.StatusBadGateway)
+	})
+
+	do := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(header, key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := do("/bullet/create", "k1", `{"a":1}`)
+	second := do("/bullet/create", "k1", `{"a":1}`)
+	if calls["create"] != 1 || second.Body.String() != first.Body.String() || second.Header().Get(replayedHeader) != "true" {
+		t.Errorf("duplicate was not replayed: %d calls, %q then %q", calls["create"], first.Body, second.Body)
+	}
+	if w := do("/bullet/create", "k1", `{"a":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	do("/auth/refresh", "k2", `{}`)
+	if w := do("/auth/refresh", "k2", `{}`); calls["refresh"] != 2 || w.Header().Get(replayedHeader) != "" {
+		t.Errorf("auth response was replayed: %d calls", calls["refresh"])
+	}
+
+	do("/fuel/create", "k3", `{}`)
+	if w := do("/fuel/create", "k3", `{}`); w.Code != http.StatusOK || calls["panic"] != 2 {
+		t.Errorf("retry after a panic = %d after %d calls, want %d", w.Code, calls["panic"], http.StatusOK)
+	}
+
+	do("/technique/create", "k4", `{}`)
+	do("/technique/create", "k4", `{}`)
+	if calls["fail"] != 2 {
+		t.Errorf("server error was remembered: %d calls", calls["fail"])
+	}
+}
@@ -0,0 +1,200 @@
+package idempotency
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is what is remembered about a request made with an Idempotency-Key.
+// Done is false while the first request is still being handled.
+type Entry struct {
+	Key      string      `json:"key"`
+	BodyHash string      `json:"body_hash"`
+	Done     bool        `json:"done"`
+	Status   int         `json:"status,omitempty"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     []byte      `json:"body,omitempty"`
+	Expires  time.Time   `json:"expires"`
+}
+
+// Store keeps idempotency entries until they expire.
+type Store interface {
+	// Reserve stores e unless an unexpired entry with the same key exists,
+	// in which case that entry is returned and reserved is false.
+	Reserve(e *Entry) (existing *Entry, reserved bool, err error)
+	// Save replaces the reservation with the finished response.
+	Save(e *Entry) error
+	// Release drops a reservation so the request can be retried.
+	Release(key string) error
+}
+
+// ErrFull is returned by Reserve when the store holds its maximum number of
+// unexpired entries.
+var ErrFull = errors.New("too many idempotency keys are in use, retry later")
+
+// sweepInterval is how often expired entries are dropped. Until then an
+// expired entry is only ignored.
+const sweepInterval = time.Minute
+
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*Entry
+	max     int
+	sweep   time.Time
+}
+
+// NewMemoryStore returns a process-local store that holds at most max
+// entries; 0 means no limit.
+func NewMemoryStore(max int) Store {
+	return newMemoryStore(max)
+}
+
+func newMemoryStore(max int) *memoryStore {
+	return &memoryStore{entries: map[string]*Entry{}, max: max, sweep: time.Now()}
+}
+
+func (s *memoryStore) Reserve(e *Entry) (*Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expire(now)
+	if existing, ok := s.entries[e.Key]; ok && !existing.Expires.Before(now) {
+		return existing, false, nil
+	} else if !ok && s.max > 0 && len(s.entries) >= s.max {
+		return nil, false, ErrFull
+	}
+	s.entries[e.Key] = e
+	return nil, true, nil
+}
+
+func (s *memoryStore) Save(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[e.Key] = e
+	return nil
+}
+
+func (s *memoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// expire drops the expired entries, at most once per sweepInterval.
+func (s *memoryStore) expire(now time.Time) {
+	if now.Sub(s.sweep) < sweepInterval {
+		return
+	}
+	s.sweep = now
+	s.drop(now)
+}
+
+func (s *memoryStore) drop(now time.Time) {
+	for key, e := range s.entries {
+		if e.Expires.Before(now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// fileStore is a memory store whose finished entries are also appended to a
+// JSON-lines file, so replays survive a gateway restart.
+type fileStore struct {
+	*memoryStore
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileStore opens a store backed by the file at path that holds at most
+// max entries; 0 means no limit. Expired entries are dropped from the file
+// when it is opened.
+func NewFileStore(path string, max int) (Store, error) {
+	s := &fileStore{memoryStore: newMemoryStore(max)}
+	if err := s.load(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := s.rewrite(path); err != nil {
+		return nil, err
+	}
+
+	var err error
+	s.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileStore) Save(e *Entry) error {
+	if err := s.memoryStore.Save(e); err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close syncs and closes the file.
+func (s *fileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+func (s *fileStore) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var e Entry
+		if err := dec.Decode(&e); err != nil {
+			return err
+		}
+		s.entries[e.Key] = &e
+	}
+	s.drop(time.Now())
+	return nil
+}
+
+func (s *fileStore) rewrite(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range s.entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
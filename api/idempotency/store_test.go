@@ -0,0 +1,40 @@
+package idempotency
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreLimit(t *testing.T) {
+	s := NewMemoryStore(2)
+	expires := time.Now().Add(time.Hour)
+	for _, key := range []string{"a", "b"} {
+		if _, reserved, err := s.Reserve(&Entry{Key: key, Expires: expires}); !reserved || err != nil {
+			t.Fatalf("Reserve(%s) = %v, %v", key, reserved, err)
+		}
+	}
+	if _, _, err := s.Reserve(&Entry{Key: "c", Expires: expires}); !errors.Is(err, ErrFull) {
+		t.Errorf("Reserve over the limit = %v, want ErrFull", err)
+	}
+	if existing, reserved, err := s.Reserve(&Entry{Key: "a", Expires: expires}); reserved || err != nil || existing == nil {
+		t.Errorf("Reserve of a held key = %v, %v, %v", existing, reserved, err)
+	}
+	if err := s.Release("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, reserved, err := s.Reserve(&Entry{Key: "c", Expires: expires}); !reserved || err != nil {
+		t.Errorf("Reserve after Release = %v, %v", reserved, err)
+	}
+}
+
+func TestMemoryStoreIgnoresExpiredEntries(t *testing.T) {
+	s := NewMemoryStore(0)
+	if _, _, err := s.Reserve(&Entry{Key: "a", Expires: time.Now().Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	// The entry is not swept yet, but must not be replayed.
+	if _, reserved, err := s.Reserve(&Entry{Key: "a", Expires: time.Now().Add(time.Hour)}); !reserved || err != nil {
+		t.Errorf("Reserve over an expired entry = %v, %v", reserved, err)
+	}
+}
@@ -0,0 +1,140 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrBreakerOpen is returned without calling the backend while a circuit
+// breaker is open. The gateway maps it to 503 Service Unavailable.
+var ErrBreakerOpen = status.Error(codes.Unavailable, "circuit breaker is open")
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	Closed BreakerState = iota
+	Open
+	HalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a consecutive-failure circuit breaker for one backend. After
+// threshold failures in a row it opens and fails every call fast; once
+// openTimeout has passed it lets a single trial call through and closes again
+// if that call succeeds.
+type Breaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewBreaker returns a closed breaker. A threshold of zero disables it.
+func NewBreaker(threshold int, openTimeout time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, openTimeout: openTimeout}
+}
+
+// State reports the current state of the breaker.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && time.Since(b.openedAt) >= b.openTimeout {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// healthService is the prefix of the gRPC health checking methods. The
+// readiness probes call them on a timer, so they bypass the breaker: a
+// passing probe must not close it while real calls keep failing.
+const healthService = "/grpc.health.v1.Health/"
+
+// Unary returns the interceptor guarding calls with the breaker.
+func (b *Breaker) Unary() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if strings.HasPrefix(method, healthService) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if !b.allow() {
+			return ErrBreakerOpen
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(failure(err))
+		return err
+	}
+}
+
+func (b *Breaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = HalfOpen
+		b.trial = true
+		return true
+	case HalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	}
+	return true
+}
+
+func (b *Breaker) record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.state = Closed
+		b.failures = 0
+		b.trial = false
+		return
+	}
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		b.state = Open
+		b.openedAt = time.Now()
+		b.trial = false
+	}
+}
+
+// failure reports whether err means the backend is unhealthy. Errors caused
+// by the request itself, such as NotFound or InvalidArgument, do not count.
+func failure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreakerIgnoresHealthChecks(t *testing.T) {
+	b := NewBreaker(2, time.Hour)
+	call := b.Unary()
+	fail := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "down")
+	}
+	ok := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return nil
+	}
+
+	// A passing probe between two failures must not reset the count.
+	call(context.Background(), "/soldiers.SoldierService/Get", nil, nil, nil, fail)
+	call(context.Background(), "/grpc.health.v1.Health/Check", nil, nil, nil, ok)
+	call(context.Background(), "/soldiers.SoldierService/Get", nil, nil, nil, fail)
+	if s := b.State(); s != Open {
+		t.Fatalf("state = %s, want open", s)
+	}
+
+	// Probes still reach the backend while the breaker is open, and do not
+	// close it.
+	if err := call(context.Background(), "/grpc.health.v1.Health/Check", nil, nil, nil, ok); err != nil {
+		t.Errorf("health check = %v, want it passed through", err)
+	}
+	if err := call(context.Background(), "/soldiers.SoldierService/Get", nil, nil, nil, ok); err != ErrBreakerOpen {
+		t.Errorf("call = %v, want ErrBreakerOpen", err)
+	}
+}
@@ -0,0 +1,81 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy configures Retry. Only methods named in Methods are retried,
+// so it must list idempotent RPCs only, e.g. "Get" or "GetAll".
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Methods        []string
+}
+
+// Retry retries idempotent calls that failed with Unavailable or
+// ResourceExhausted, sleeping a full-jitter exponential backoff between
+// attempts. It gives up early when the call's context is done or a circuit
+// breaker is open.
+func Retry(p RetryPolicy) grpc.UnaryClientInterceptor {
+	methods := make(map[string]bool, len(p.Methods))
+	for _, m := range p.Methods {
+		methods[m] = true
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if p.MaxAttempts <= 1 || !methods[path.Base(method)] {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		var err error
+		for attempt := 0; attempt < p.MaxAttempts; attempt++ {
+			if attempt > 0 {
+				timer := time.NewTimer(backoff(p, attempt))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+			}
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if !retryable(err) {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+func retryable(err error) bool {
+	if err == nil || errors.Is(err, ErrBreakerOpen) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// backoff returns a random delay in [0, min(MaxBackoff, InitialBackoff*2^(attempt-1))].
+func backoff(p RetryPolicy, attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"strings"
+)
+
+// Options configures the logger.
+type Options struct {
+	// Level is debug, info, warn or error.
+	Level string
+	// SampleRate is the fraction of debug and info records written. Warnings
+	// and errors are always written.
+	SampleRate float64
+}
+
+// New returns a JSON logger writing to w.
+func New(w io.Writer, o Options) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(o.Level))); err != nil {
+		return nil, fmt.Errorf("log level %q: %w", o.Level, err)
+	}
+	var h slog.Handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	if o.SampleRate < 1 {
+		h = &samplingHandler{Handler: h, rate: o.SampleRate}
+	}
+	return slog.New(h), nil
+}
+
+// samplingHandler drops a random share of the records below warning level.
+type samplingHandler struct {
+	slog.Handler
+	rate float64
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn && rand.Float64() >= h.rate {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), rate: h.rate}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), rate: h.rate}
+}
@@ -0,0 +1,163 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// maxLoggedBody caps the request and response bodies logged at debug level.
+const maxLoggedBody = 4096
+
+// redactedFields are masked wherever they appear in a logged body. They
+// cover the personal data of soldiers and commanders and all credentials.
+var redactedFields = map[string]bool{
+	"email":         true,
+	"phone_number":  true,
+	"date_of_birth": true,
+	"password":      true,
+	"access_token":  true,
+	"refresh_token": true,
+}
+
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	if n := maxLoggedBody - w.body.Len(); n > 0 {
+		w.body.Write(b[:min(n, len(b))])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// peekedBody is a request body whose first bytes were read for the log.
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to extend
+// the write deadline of a streaming response.
+func (w *bodyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Middleware logs one record per request with the route, status, latency,
+// actor and request ID. Server errors are logged at error level and client
+// errors at warning level. When debug is enabled the redacted request and
+// response bodies are included.
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		logger := slog.Default()
+		start := time.Now()
+		withBodies := logger.Enabled(ctx, slog.LevelDebug)
+
+		var body []byte
+		var w *bodyWriter
+		if withBodies {
+			if rb := ctx.Request.Body; rb != nil {
+				// Only the logged part is read ahead; the handler reads the
+				// rest from the client as usual.
+				body, _ = io.ReadAll(io.LimitReader(rb, maxLoggedBody))
+				ctx.Request.Body = peekedBody{io.MultiReader(bytes.NewReader(body), rb), rb}
+			}
+			w = &bodyWriter{ResponseWriter: ctx.Writer}
+			ctx.Writer = w
+		}
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("request_id", ctx.GetString("request_id")),
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.Int("bytes", ctx.Writer.Size()),
+		}
+		if actor := ctx.GetString("user_id"); actor != "" {
+			attrs = append(attrs, slog.String("actor", actor), slog.String("role", ctx.GetString("role")))
+		}
+		if errs := ctx.Errors.String(); errs != "" {
+			attrs = append(attrs, slog.String("error", errs))
+		}
+		if withBodies {
+			attrs = append(attrs,
+				slog.Any("request_body", Redact(body)),
+				slog.Any("response_body", Redact(w.body.Bytes())),
+			)
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
+
+// Recovery turns a panic in a handler into a logged error and a 500 response.
+func Recovery() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.ErrorContext(ctx, "panic while handling request",
+					"request_id", ctx.GetString("request_id"),
+					"panic", r,
+					"stack", string(debug.Stack()),
+				)
+				apierror.Abort(ctx, codes.Internal, "internal server error")
+			}
+		}()
+		ctx.Next()
+	}
+}
+
+// Redact parses body as JSON and masks the redacted fields at any depth.
+// Bodies that are not JSON are replaced by their length.
+func Redact(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return strconv.Itoa(len(body)) + " bytes"
+	}
+	return redact(v)
+}
+
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redactedFields[key] {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			v[key] = redact(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+	}
+	return v
+}
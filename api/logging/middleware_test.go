@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddlewareCapsRequestBody(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, Options{Level: "debug", SampleRate: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(old) })
+
+	gin.SetMode(gin.TestMode)
+	var got int
+	r := gin.New()
+	r.Use(Middleware())
+	r.POST("/bullet/create", func(ctx *gin.Context) {
+		b, _ := io.ReadAll(ctx.Request.Body)
+		got = len(b)
+	})
+
+	body := strings.Repeat("x", 3*maxLoggedBody)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/bullet/create", strings.NewReader(body)))
+
+	if got != len(body) {
+		t.Errorf("handler read %d bytes, want %d", got, len(body))
+	}
+	var record struct {
+		RequestBody string `json:"request_body"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if want := "4096 bytes"; record.RequestBody != want {
+		t.Errorf("request_body = %q, want %q", record.RequestBody, want)
+	}
+}
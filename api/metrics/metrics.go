@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_http_requests_total",
+		Help: "HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gateway_http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route template and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	httpInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "gateway_http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	grpcCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_grpc_client_calls_total",
+		Help: "gRPC calls to the backends by service, method and status code.",
+	}, []string{"service", "method", "code"})
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gateway_grpc_client_call_duration_seconds",
+		Help:    "gRPC call latency, retries included, by service, method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"service", "method", "code"})
+	grpcInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gateway_grpc_client_calls_in_flight",
+		Help: "gRPC calls to the backends currently in progress by service.",
+	}, []string{"service"})
+
+	bulletsSubtracted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "military_bullets_subtracted_total",
+		Help: "Bullets taken from stock by bullet type and source (use or sub).",
+	}, []string{"type", "source"})
+	fuelConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "military_fuel_consumed_litres_total",
+		Help: "Fuel litres taken from stock by fuel type and source (use or sub).",
+	}, []string{"type", "source"})
+	techniquesSubtracted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "military_techniques_subtracted_total",
+		Help: "Techniques taken from stock by name.",
+	}, []string{"type"})
+)
+
+// Sources of stock changes.
+const (
+	SourceUse = "use"
+	SourceSub = "sub"
+)
+
+// OtherType is the type label of stock whose name is not a known type.
+// Names come from request bodies, so only known ones become labels.
+const OtherType = "other"
+
+var (
+	bulletTypes    = map[string]bool{"weapon": true, "military vehicle": true}
+	fuelTypes      = map[string]bool{"diesel": true, "petrol": true}
+	techniqueTypes = map[string]bool{}
+)
+
+// SetTechniqueTypes sets the technique names counted under their own label;
+// the others are counted as OtherType.
+func SetTechniqueTypes(names []string) {
+	techniqueTypes = map[string]bool{}
+	for _, name := range names {
+		techniqueTypes[name] = true
+	}
+}
+
+func typeLabel(known map[string]bool, name string) string {
+	if known[name] {
+		return name
+	}
+	return OtherType
+}
+
+// Handler serves the default registry in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// NewServer returns a server for a private admin listener that serves only
+// /metrics, so the gateway's own port need not expose it.
+func NewServer(readHeaderTimeout time.Duration) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+	return &http.Server{Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+}
+
+// Middleware records the count, latency and in-flight number of HTTP
+// requests. Requests matching no route are labelled "unmatched" to keep the
+// label set bounded.
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+		start := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"method": ctx.Request.Method,
+			"route":  route,
+			"status": strconv.Itoa(ctx.Writer.Status()),
+		}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+// UnaryClientInterceptor records the count, latency and in-flight number of
+// gRPC calls.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service := strings.TrimPrefix(path.Dir(method), "/")
+		inFlight := grpcInFlight.WithLabelValues(service)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		labels := prometheus.Labels{
+			"service": service,
+			"method":  path.Base(method),
+			"code":    status.Code(err).String(),
+		}
+		grpcCalls.With(labels).Inc()
+		grpcDuration.With(labels).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// BulletsSubtracted counts n bullets of type taken from stock. Types other
+// than weapon and military vehicle are counted as OtherType.
+func BulletsSubtracted(typ, source string, n int32) {
+	if n > 0 {
+		bulletsSubtracted.WithLabelValues(typeLabel(bulletTypes, typ), source).Add(float64(n))
+	}
+}
+
+// FuelConsumed counts n litres of fuel of type taken from stock. Types other
+// than diesel and petrol are counted as OtherType.
+func FuelConsumed(typ, source string, n int32) {
+	if n > 0 {
+		fuelConsumed.WithLabelValues(typeLabel(fuelTypes, typ), source).Add(float64(n))
+	}
+}
+
+// TechniquesSubtracted counts n techniques named typ taken from stock. Names
+// not set with SetTechniqueTypes are counted as OtherType.
+func TechniquesSubtracted(typ string, n int32) {
+	if n > 0 {
+		techniquesSubtracted.WithLabelValues(typeLabel(techniqueTypes, typ)).Add(float64(n))
+	}
+}
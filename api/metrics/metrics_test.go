@@ -0,0 +1,25 @@
+package metrics
+
+import "testing"
+
+func TestTypeLabel(t *testing.T) {
+	SetTechniqueTypes([]string{"tank"})
+	t.Cleanup(func() { SetTechniqueTypes(nil) })
+	tests := []struct {
+		known map[string]bool
+		name  string
+		want  string
+	}{
+		{bulletTypes, "weapon", "weapon"},
+		{bulletTypes, "x-1f3a", OtherType},
+		{fuelTypes, "diesel", "diesel"},
+		{fuelTypes, "", OtherType},
+		{techniqueTypes, "tank", "tank"},
+		{techniqueTypes, "drone", OtherType},
+	}
+	for _, tt := range tests {
+		if got := typeLabel(tt.known, tt.name); got != tt.want {
+			t.Errorf("typeLabel(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
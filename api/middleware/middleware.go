@@ -1,27 +1,103 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/Salikhov079/military/api/apierror"
 	t "github.com/Salikhov079/military/api/token"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
 )
 
-func MiddleWare() gin.HandlerFunc {
+// publicRoutes are the route templates served without a token.
+var publicRoutes = map[string]bool{
+	"/auth/login":   true,
+	"/auth/refresh": true,
+	"/healthz":      true,
+	"/readyz":       true,
+	"/livez":        true,
+	"/swagger/*any": true,
+}
+
+// queryTokenRoutes may take the token from the access_token query parameter:
+// EventSource and browser WebSocket clients cannot set headers. Elsewhere the
+// parameter would only put tokens in access logs and browser history.
+var queryTokenRoutes = map[string]bool{
+	"/ai/chat/stream": true,
+	"/ai/chat/ws":     true,
+}
+
+// legacyHeader is the misspelled header older clients send the token in.
+const legacyHeader = "Authourization"
+
+func MiddleWare(policy *Policy, allowLegacyHeader bool) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		token := ctx.GetHeader("Authourization")
-		url := ctx.Request.URL.Path
-		if strings.Contains(url, "swagger"){
+		if publicRoutes[ctx.FullPath()] {
 			ctx.Next()
 			return
-		} else if _, err := t.ExtractClaim(token); err != nil {
-			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": err.Error(),
-			})
+		}
+		token, err := bearerToken(ctx, allowLegacyHeader)
+		if err != nil {
+			unauthorized(ctx, "invalid_request", err)
+			return
+		}
+		claims, err := t.ExtractClaim(token)
+		if err != nil {
+			unauthorized(ctx, "invalid_token", err)
+			return
+		}
+
+		role := t.Role(claims)
+		if role == "" {
+			apierror.Abort(ctx, codes.PermissionDenied, "token has no role claim")
+			return
+		}
+		// Paths that match no route have no template and are denied like
+		// routes the policy does not list.
+		route := ctx.FullPath()
+		if !policy.Allow(role, ctx.Request.Method, route) {
+			apierror.Abort(ctx, codes.PermissionDenied,
+				fmt.Sprintf("role %q is not allowed to %s %s", role, ctx.Request.Method, ctx.Request.URL.Path))
 			return
 		}
+
+		ctx.Set("token", token)
+		ctx.Set("claims", claims)
+		ctx.Set("user_id", t.Subject(claims))
+		ctx.Set("role", role)
 		ctx.Next()
+	}
+}
 
+// bearerToken reads the access token as described in RFC 6750: from the
+// "Authorization: Bearer" header, or from the access_token query parameter on
+// the queryTokenRoutes. The legacy
+// header is accepted with a deprecation warning while allowLegacyHeader is on.
+func bearerToken(ctx *gin.Context, allowLegacyHeader bool) (string, error) {
+	if header := ctx.GetHeader("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			return "", errors.New("authorization header must be of the form \"Bearer <token>\"")
+		}
+		return strings.TrimSpace(token), nil
 	}
+	if token := ctx.Query("access_token"); token != "" && queryTokenRoutes[ctx.FullPath()] {
+		return token, nil
+	}
+	if header := ctx.GetHeader(legacyHeader); header != "" && allowLegacyHeader {
+		ctx.Header("Deprecation", "true")
+		ctx.Header("Warning", `299 - "The Authourization header is deprecated, use Authorization: Bearer <token>"`)
+		if scheme, token, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token), nil
+		}
+		return header, nil
+	}
+	return "", errors.New("missing bearer token")
+}
+
+func unauthorized(ctx *gin.Context, code string, err error) {
+	ctx.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error=%q, error_description=%q`, code, err.Error()))
+	apierror.Abort(ctx, codes.Unauthenticated, err.Error())
 }
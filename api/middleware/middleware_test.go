@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Salikhov079/military/api/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleWare(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(MiddleWare(loadTestPolicy(t), false))
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	r.GET("/swagger/*any", ok)
+	r.GET("/healthz", ok)
+	r.GET("/metrics", ok)
+	r.DELETE("/soldier/delete/:id", ok)
+	r.GET("/bullet/getall", ok)
+	r.GET("/bullet/get/:id", ok)
+	r.GET("/ai/chat/stream", ok)
+
+	issue := func(role string) string {
+		tokens, err := token.GenerateTokens(&token.Principal{ID: "u1", Role: role})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokens.AccessToken
+	}
+	commander, admin := issue("commander"), issue("admin")
+
+	tests := []struct {
+		name, method, path, token string
+		want                      int
+	}{
+		{"swagger is public", "GET", "/swagger/index.html", "", http.StatusOK},
+		{"health is public", "GET", "/healthz", "", http.StatusOK},
+		{"metrics need a token", "GET", "/metrics", "", http.StatusUnauthorized},
+		{"metrics outside policy", "GET", "/metrics", commander, http.StatusForbidden},
+		{"metrics for admins", "GET", "/metrics", admin, http.StatusOK},
+		{"swagger in a path is not public", "DELETE", "/soldier/delete/swagger", "", http.StatusUnauthorized},
+		{"unmatched path without token", "GET", "/docs/swagger/x", "", http.StatusUnauthorized},
+		{"allowed route", "DELETE", "/soldier/delete/s1", commander, http.StatusOK},
+		{"route outside policy", "GET", "/bullet/get/b1", commander, http.StatusForbidden},
+		{"unmatched path is denied", "GET", "/nowhere", commander, http.StatusForbidden},
+		{"unmatched path is denied to admins", "GET", "/nowhere", admin, http.StatusForbidden},
+		{"query token on a stream", "GET", "/ai/chat/stream?access_token=" + admin, "", http.StatusOK},
+		{"query token elsewhere", "GET", "/bullet/getall?access_token=" + commander, "", http.StatusUnauthorized},
+		{"garbage token", "GET", "/bullet/getall", "garbage", http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Errorf("%s %s = %d, want %d: %s", tc.method, tc.path, w.Code, tc.want, w.Body)
+			}
+		})
+	}
+}
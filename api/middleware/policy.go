@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is a per-role permission table over the routes registered in api.NewGin.
+type Policy struct {
+	Roles map[string][]string `yaml:"roles"`
+
+	rules map[string][]rule
+}
+
+type rule struct {
+	method string
+	path   string
+	prefix bool
+}
+
+// LoadPolicy reads a policy file. Every rule has the form "METHOD /route",
+// where route is the gin route template (e.g. "DELETE /soldier/delete/:id").
+// "*" matches any method and a trailing "*" matches every route under a prefix.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+
+	p.rules = make(map[string][]rule, len(p.Roles))
+	for role, lines := range p.Roles {
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("policy %s: role %q: invalid rule %q", path, role, line)
+			}
+			r := rule{method: strings.ToUpper(fields[0]), path: fields[1]}
+			if strings.HasSuffix(r.path, "*") {
+				r.prefix = true
+				r.path = strings.TrimSuffix(r.path, "*")
+			}
+			p.rules[role] = append(p.rules[role], r)
+		}
+	}
+	return &p, nil
+}
+
+// Allow reports whether role may call method on the given route template.
+func (p *Policy) Allow(role, method, route string) bool {
+	for _, r := range p.rules[role] {
+		if r.method != "*" && r.method != method {
+			continue
+		}
+		if r.path == route || (r.prefix && strings.HasPrefix(route, r.path)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPolicy = `
+roles:
+  admin:
+    - "* /*"
+  commander:
+    - "* /soldier/*"
+    - "GET /bullet/getall"
+`
+
+func loadTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPolicyAllow(t *testing.T) {
+	p := loadTestPolicy(t)
+	tests := []struct {
+		role, method, route string
+		want                bool
+	}{
+		{"admin", "DELETE", "/soldier/delete/:id", true},
+		{"admin", "GET", "", false},
+		{"commander", "DELETE", "/soldier/delete/:id", true},
+		{"commander", "GET", "/bullet/getall", true},
+		{"commander", "POST", "/bullet/getall", false},
+		{"commander", "GET", "/bullet/get/:id", false},
+		{"commander", "GET", "/soldierx", false},
+		{"commander", "GET", "", false},
+		{"soldier", "GET", "/bullet/getall", false},
+	}
+	for _, tt := range tests {
+		if got := p.Allow(tt.role, tt.method, tt.route); got != tt.want {
+			t.Errorf("Allow(%q, %q, %q) = %v, want %v", tt.role, tt.method, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestLoadPolicyInvalidRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("roles:\n  admin:\n    - \"/soldier/*\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPolicy(path); err == nil {
+		t.Fatal("LoadPolicy accepted a rule without a method")
+	}
+}
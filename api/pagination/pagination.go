@@ -0,0 +1,282 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Salikhov079/military/api/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxLimit is the largest page a client may ask for.
+const MaxLimit = 100
+
+var (
+	defaultLimit  = 10
+	defaultOffset = 0
+)
+
+// SetDefaults sets the limit and offset used when a request has none.
+func SetDefaults(limit, offset int) {
+	if limit > 0 {
+		defaultLimit = limit
+	}
+	if offset >= 0 {
+		defaultOffset = offset
+	}
+}
+
+// Meta describes the returned page of a list.
+type Meta struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// cursor is the decoded form of next_cursor. It pins the sort order so that a
+// cursor cannot be replayed against a differently sorted list.
+type cursor struct {
+	Offset int    `json:"o"`
+	Sort   string `json:"s,omitempty"`
+}
+
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// Paginate sorts and pages items as asked by the limit, offset, cursor and
+// sort query parameters and sets the RFC 8288 Link header. The backends
+// return whole lists, so paging happens in the gateway. On invalid
+// parameters it writes an application/problem+json response and returns
+// false.
+//
+// sort is a comma-separated list of JSON field names, each optionally
+// prefixed with "-" for descending order, e.g. "name,-date_of_birth". cursor
+// is an opaque next_cursor from a previous page and takes precedence over
+// offset.
+func Paginate[T any](ctx *gin.Context, items []T) ([]T, Meta, bool) {
+	var params []apierror.InvalidParam
+	invalid := func(name, reason string) {
+		params = append(params, apierror.InvalidParam{Name: name, Reason: reason})
+	}
+
+	limit, offset := defaultLimit, defaultOffset
+	if v := ctx.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxLimit {
+			invalid("limit", fmt.Sprintf("must be an integer between 1 and %d", MaxLimit))
+		}
+		limit = n
+	}
+	if v := ctx.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			invalid("offset", "must be a non-negative integer")
+		}
+		offset = n
+	}
+
+	sortParam := ctx.Query("sort")
+	keys, err := parseSort(sortParam, reflect.TypeOf(items).Elem())
+	if err != nil {
+		invalid("sort", err.Error())
+	}
+	if v := ctx.Query("cursor"); v != "" {
+		c, err := decodeCursor(v)
+		switch {
+		case err != nil:
+			invalid("cursor", "is not a valid cursor")
+		case c.Sort != sortParam:
+			invalid("cursor", "was issued for a different sort order")
+		default:
+			offset = c.Offset
+		}
+	}
+	if len(params) > 0 {
+		apierror.AbortWithValidation(ctx, "", params)
+		return nil, Meta{}, false
+	}
+
+	sortItems(items, keys)
+	meta := Meta{Total: len(items), Limit: limit, Offset: offset}
+	// offset comes from the client and may be near the largest int, so it
+	// is clamped before anything is added to it.
+	start := min(offset, len(items))
+	end := start + min(limit, len(items)-start)
+	if end < len(items) {
+		meta.NextCursor = encodeCursor(cursor{Offset: end, Sort: sortParam})
+	}
+	setLinks(ctx, meta)
+	return items[start:end], meta, true
+}
+
+func parseSort(param string, elem reflect.Type) ([]sortKey, error) {
+	if param == "" {
+		return nil, nil
+	}
+	var keys []sortKey
+	for _, f := range strings.Split(param, ",") {
+		k := sortKey{field: strings.TrimSpace(f)}
+		if strings.HasPrefix(k.field, "-") {
+			k.field, k.desc = k.field[1:], true
+		}
+		if _, ok := fieldIndex(elem, k.field); !ok {
+			return nil, fmt.Errorf("cannot sort by %q", k.field)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// fieldIndex finds the sortable field of the struct (or pointer to struct)
+// type t whose JSON name is name.
+func fieldIndex(t reflect.Type, name string) (int, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return 0, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || tag != name {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return i, true
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+func sortItems[T any](items []T, keys []sortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	elem := reflect.TypeOf(items).Elem()
+	index := make([]int, len(keys))
+	for i, k := range keys {
+		index[i], _ = fieldIndex(elem, k.field)
+	}
+	value := func(i int) reflect.Value {
+		v := reflect.ValueOf(items[i])
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		return v
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := value(i), value(j)
+		if !a.IsValid() || !b.IsValid() {
+			return b.IsValid()
+		}
+		for n, k := range keys {
+			c := compare(a.Field(index[n]), b.Field(index[n]))
+			if c == 0 {
+				continue
+			}
+			if k.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func compare(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case b.Bool():
+			return -1
+		}
+		return 1
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return cmpOrdered(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return cmpOrdered(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmpOrdered(a.Float(), b.Float())
+	}
+	return 0
+}
+
+func cmpOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+	if c.Offset < 0 {
+		return c, fmt.Errorf("negative offset")
+	}
+	return c, nil
+}
+
+// setLinks writes the first, prev, next and last page links of meta.
+func setLinks(ctx *gin.Context, meta Meta) {
+	link := func(rel string, offset int) string {
+		u := *ctx.Request.URL
+		q := u.Query()
+		q.Del("cursor")
+		q.Set("limit", strconv.Itoa(meta.Limit))
+		q.Set("offset", strconv.Itoa(offset))
+		if rel == "next" && meta.NextCursor != "" {
+			q.Del("offset")
+			q.Set("cursor", meta.NextCursor)
+		}
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", (&url.URL{Path: u.Path, RawQuery: u.RawQuery}).String(), rel)
+	}
+
+	links := []string{link("first", 0)}
+	if meta.Offset > 0 {
+		links = append(links, link("prev", max(min(meta.Offset, meta.Total)-meta.Limit, 0)))
+	}
+	if meta.NextCursor != "" {
+		// The cursor takes the place of the offset.
+		links = append(links, link("next", 0))
+	}
+	if meta.Total > 0 {
+		links = append(links, link("last", (meta.Total-1)/meta.Limit*meta.Limit))
+	}
+	ctx.Header("Link", strings.Join(links, ", "))
+}
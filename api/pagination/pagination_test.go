@@ -0,0 +1,74 @@
+package pagination
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func paginate(t *testing.T, query string) ([]item, Meta, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/bullet/getall?"+query, nil)
+	items := []item{{1, "c"}, {2, "a"}, {3, "b"}, {4, "e"}, {5, "d"}}
+	page, meta, _ := Paginate(ctx, items)
+	return page, meta, w
+}
+
+func ids(items []item) []int {
+	res := []int{}
+	for _, it := range items {
+		res = append(res, it.ID)
+	}
+	return res
+}
+
+func TestPaginate(t *testing.T) {
+	page, meta, w := paginate(t, "limit=2&sort=-name")
+	if got := ids(page); !reflect.DeepEqual(got, []int{4, 5}) {
+		t.Fatalf("first page = %v", got)
+	}
+	if !strings.Contains(w.Header().Get("Link"), `rel="next"`) {
+		t.Errorf("Link = %q, want a next link", w.Header().Get("Link"))
+	}
+
+	page, meta, _ = paginate(t, "limit=2&sort=-name&cursor="+meta.NextCursor)
+	if got := ids(page); !reflect.DeepEqual(got, []int{1, 3}) || meta.Offset != 2 {
+		t.Fatalf("second page = %v at offset %d", got, meta.Offset)
+	}
+
+	if _, _, w := paginate(t, "limit=2&cursor="+meta.NextCursor); w.Code != http.StatusBadRequest {
+		t.Errorf("cursor of another sort order = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPaginateLargeOffset(t *testing.T) {
+	huge := strconv.Itoa(math.MaxInt)
+	for name, query := range map[string]string{
+		"offset": "limit=100&offset=" + huge,
+		"cursor": "limit=100&cursor=" + encodeCursor(cursor{Offset: math.MaxInt}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			page, meta, w := paginate(t, query)
+			if w.Code != http.StatusOK || len(page) != 0 || meta.NextCursor != "" {
+				t.Fatalf("page = %v, meta = %+v, status %d", page, meta, w.Code)
+			}
+			if link := w.Header().Get("Link"); !strings.Contains(link, `offset=0>; rel="prev"`) {
+				t.Errorf("Link = %q, want the prev link to start at 0", link)
+			}
+		})
+	}
+}
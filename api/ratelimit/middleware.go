@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+)
+
+// Limiter enforces quotas per caller and route group.
+type Limiter struct {
+	store  Store
+	limits *Limits
+}
+
+// New returns a limiter keeping its buckets in store. quotas are parsed as
+// in NewLimits.
+func New(store Store, quotas map[string]string) (*Limiter, error) {
+	limits, err := NewLimits(quotas)
+	if err != nil {
+		return nil, err
+	}
+	return &Limiter{store: store, limits: limits}, nil
+}
+
+// Middleware limits each caller to the quota of the route group it calls.
+// Callers are identified by the JWT subject the authentication middleware
+// verified, so it must run after it, and otherwise by client IP. Nothing the
+// client sends unverified picks the bucket; the gateway issues no API keys,
+// so an API key header could not be verified and is not used. Every
+// response gets the RateLimit-* headers; rejected requests get 429 and
+// Retry-After. When the store fails, requests are let through.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		if route == "" {
+			route = ctx.Request.URL.Path
+		}
+		if exempt(route) {
+			ctx.Next()
+			return
+		}
+		group, q, ok := l.limits.For(route)
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		res, err := l.store.Take("ratelimit:"+group+":"+caller(ctx), q)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error while checking rate limit", "request_id", ctx.GetString("request_id"), "error", err)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("RateLimit-Policy", q.String())
+		ctx.Header("RateLimit-Limit", strconv.Itoa(q.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			retry := ceilSeconds(res.RetryAfter)
+			ctx.Header("Retry-After", strconv.Itoa(retry))
+			apierror.Abort(ctx, codes.ResourceExhausted,
+				fmt.Sprintf("rate limit of %d requests per %s exceeded, retry in %d s", q.Limit, q.Period, retry))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func caller(ctx *gin.Context) string {
+	if sub := ctx.GetString("user_id"); sub != "" {
+		return "sub:" + sub
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+// exempt reports whether route is an infrastructure endpoint polled by
+// orchestrators and scrapers.
+func exempt(route string) bool {
+	switch route {
+	case "/healthz", "/readyz", "/livez", "/metrics":
+		return true
+	}
+	return strings.HasPrefix(route, "/swagger")
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
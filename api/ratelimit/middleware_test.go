@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(t *testing.T, proxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	l, err := New(NewMemoryStore(), map[string]string{DefaultGroup: "2/1m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		t.Fatal(err)
+	}
+	// Stands in for the authentication middleware.
+	r.Use(func(ctx *gin.Context) {
+		if sub := ctx.GetHeader("X-Test-Subject"); sub != "" {
+			ctx.Set("user_id", sub)
+		}
+	})
+	r.Use(l.Middleware())
+	r.GET("/bullet/getall", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	return r
+}
+
+func get(r *gin.Engine, header map[string]string) int {
+	req := httptest.NewRequest(http.MethodGet, "/bullet/getall", nil)
+	req.RemoteAddr = "192.0.2.1:4000"
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestMiddlewareIgnoresUnverifiedHeaders(t *testing.T) {
+	r := newRouter(t, nil)
+	for i, h := range []map[string]string{
+		{"X-API-Key": "a"},
+		{"X-API-Key": "b", "X-Forwarded-For": "198.51.100.1"},
+		{"X-API-Key": "c", "X-Forwarded-For": "198.51.100.2"},
+	} {
+		want := http.StatusOK
+		if i == 2 {
+			want = http.StatusTooManyRequests
+		}
+		if got := get(r, h); got != want {
+			t.Errorf("request %d = %d, want %d", i+1, got, want)
+		}
+	}
+	if got := get(r, map[string]string{"X-Test-Subject": "u1"}); got != http.StatusOK {
+		t.Errorf("authenticated caller = %d, want its own bucket", got)
+	}
+}
+
+func TestMiddlewareTrustedProxy(t *testing.T) {
+	r := newRouter(t, []string{"192.0.2.1"})
+	for i := 0; i < 2; i++ {
+		get(r, map[string]string{"X-Forwarded-For": "198.51.100.1"})
+	}
+	if got := get(r, map[string]string{"X-Forwarded-For": "198.51.100.1"}); got != http.StatusTooManyRequests {
+		t.Errorf("third request of a client = %d, want %d", got, http.StatusTooManyRequests)
+	}
+	if got := get(r, map[string]string{"X-Forwarded-For": "198.51.100.2"}); got != http.StatusOK {
+		t.Errorf("other client behind the proxy = %d, want %d", got, http.StatusOK)
+	}
+}
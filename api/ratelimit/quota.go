@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultGroup names the quota of routes that match no other prefix.
+const DefaultGroup = "default"
+
+// Quota allows Limit requests per Period. It is also the bucket size, so a
+// caller with a full bucket may send Limit requests at once.
+type Quota struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParseQuota parses a quota written as "requests/period", e.g. "20/1m".
+func ParseQuota(s string) (Quota, error) {
+	n, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Quota{}, fmt.Errorf("quota %q: want requests/period", s)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil || limit < 1 {
+		return Quota{}, fmt.Errorf("quota %q: requests must be a positive integer", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Quota{}, fmt.Errorf("quota %q: period must be a positive duration", s)
+	}
+	return Quota{Limit: limit, Period: d}, nil
+}
+
+// String formats q as a RateLimit-Policy value.
+func (q Quota) String() string {
+	return fmt.Sprintf("%d;w=%d", q.Limit, int(q.Period.Seconds()))
+}
+
+// Limits maps route prefixes to quotas.
+type Limits struct {
+	prefixes []string
+	quotas   map[string]Quota
+}
+
+// NewLimits parses quotas keyed by route prefix, such as "/ai", or by
+// DefaultGroup.
+func NewLimits(quotas map[string]string) (*Limits, error) {
+	l := &Limits{quotas: make(map[string]Quota, len(quotas))}
+	for prefix, s := range quotas {
+		q, err := ParseQuota(s)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit %s: %w", prefix, err)
+		}
+		l.quotas[prefix] = q
+		if prefix != DefaultGroup {
+			l.prefixes = append(l.prefixes, strings.TrimSuffix(prefix, "/"))
+		}
+	}
+	// Longest prefix first, so the most specific group wins.
+	sort.Slice(l.prefixes, func(i, j int) bool { return len(l.prefixes[i]) > len(l.prefixes[j]) })
+	return l, nil
+}
+
+// For returns the group and quota of route. ok is false when route matches
+// no prefix and there is no default quota.
+func (l *Limits) For(route string) (group string, q Quota, ok bool) {
+	for _, p := range l.prefixes {
+		if route == p || strings.HasPrefix(route, p+"/") {
+			return p, l.quotas[p], true
+		}
+	}
+	q, ok = l.quotas[DefaultGroup]
+	return DefaultGroup, q, ok
+}
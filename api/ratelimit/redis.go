@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Salikhov079/military/api/redis"
+)
+
+// takeScript refills and takes from the bucket atomically on the server,
+// using the server's clock so that replicas with skewed clocks agree.
+// ARGV are the capacity and the refill rate in tokens per millisecond. It
+// returns allowed (0 or 1), the remaining tokens and the milliseconds until
+// the next token and until the bucket is full.
+const takeScript = `
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(b[1]) or capacity
+local ts = tonumber(b[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed, retry = 0, 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], reset + 1000)
+return {allowed, math.floor(tokens), retry, reset}
+`
+
+// redisStore keeps the buckets in a Redis-compatible server so that every
+// gateway replica draws from the same budget. The server must support EVAL.
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore returns a store using client.
+func NewRedisStore(client *redis.Client) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Take(key string, q Quota) (Result, error) {
+	rate := float64(q.Limit) / float64(q.Period.Milliseconds())
+	v, err := s.client.Do("EVAL", takeScript, "1", key,
+		strconv.Itoa(q.Limit), strconv.FormatFloat(rate, 'g', -1, 64))
+	if err != nil {
+		return Result{}, err
+	}
+	reply, ok := v.([]interface{})
+	if !ok || len(reply) != 4 {
+		return Result{}, fmt.Errorf("redis: unexpected EVAL reply %v", v)
+	}
+	n := make([]int64, 4)
+	for i, r := range reply {
+		if n[i], ok = r.(int64); !ok {
+			return Result{}, fmt.Errorf("redis: unexpected EVAL reply %v", v)
+		}
+	}
+	return Result{
+		Allowed:    n[0] == 1,
+		Remaining:  int(n[1]),
+		RetryAfter: time.Duration(n[2]) * time.Millisecond,
+		Reset:      time.Duration(n[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token, when not Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets.
+type Store interface {
+	// Take takes one token from the bucket under key, refilled at q.
+	Take(key string, q Quota) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// period is the refill period of the quota the bucket was last taken
+	// from; after it the bucket is full again.
+	period time.Duration
+}
+
+// sweepInterval is how often idle buckets are dropped from memory.
+const sweepInterval = time.Minute
+
+// memoryStore keeps the buckets of one gateway process.
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweep   time.Time
+}
+
+// NewMemoryStore returns a process-local store.
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: map[string]*bucket{}, sweep: time.Now()}
+}
+
+func (s *memoryStore) Take(key string, q Quota) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expire(now)
+	capacity := float64(q.Limit)
+	rate := capacity / q.Period.Seconds()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last, b.period = now, q.Period
+
+	var res Result
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / rate)
+	return res, nil
+}
+
+// expire drops, at most once per sweepInterval, the buckets that have been
+// idle long enough to be full again under their own quota.
+func (s *memoryStore) expire(now time.Time) {
+	if now.Sub(s.sweep) < sweepInterval {
+		return
+	}
+	s.sweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
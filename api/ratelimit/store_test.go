@@ -0,0 +1,27 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreExpiresByBucketPeriod(t *testing.T) {
+	s := NewMemoryStore().(*memoryStore)
+	strict := Quota{Limit: 1, Period: time.Hour}
+	loose := Quota{Limit: 10, Period: time.Second}
+
+	if res, _ := s.Take("strict", strict); !res.Allowed {
+		t.Fatal("first request was refused")
+	}
+	// The strict bucket has been idle longer than the loose period, and a
+	// sweep is due.
+	s.buckets["strict"].last = time.Now().Add(-10 * time.Second)
+	s.sweep = time.Now().Add(-2 * sweepInterval)
+
+	if res, _ := s.Take("loose", loose); !res.Allowed {
+		t.Fatal("loose request was refused")
+	}
+	if res, _ := s.Take("strict", strict); res.Allowed {
+		t.Error("a request on a loose route refilled the strict bucket")
+	}
+}
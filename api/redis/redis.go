@@ -0,0 +1,166 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Options configures a connection to a Redis-compatible server.
+type Options struct {
+	Addr     string
+	Password string
+	DB       int
+	Timeout  time.Duration
+}
+
+// Client speaks the RESP protocol to Redis or any server implementing the
+// commands it is sent. Commands are serialized over a single connection that
+// is re-dialled after an error.
+type Client struct {
+	opts Options
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// Dial connects to the server at opts.Addr, authenticating and selecting the
+// database when configured.
+func Dial(opts Options) (*Client, error) {
+	c := &Client{opts: opts}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// Do sends a command and returns its reply: a string for simple strings,
+// int64 for integers, []byte for bulk strings, nil for a missing value and
+// []interface{} for arrays. Error replies are returned as Error.
+func (c *Client) Do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return nil, err
+		}
+	}
+	v, err := c.roundTrip(args...)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		c.conn.Close()
+		c.conn = nil
+	}
+	return v, err
+}
+
+// connect dials the server and authenticates. The caller holds c.mu.
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout("tcp", c.opts.Addr, c.opts.Timeout)
+	if err != nil {
+		return fmt.Errorf("redis %s: %w", c.opts.Addr, err)
+	}
+	c.conn, c.rd = conn, bufio.NewReader(conn)
+
+	var setup [][]string
+	if c.opts.Password != "" {
+		setup = append(setup, []string{"AUTH", c.opts.Password})
+	}
+	if c.opts.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.opts.DB)})
+	}
+	for _, cmd := range setup {
+		if _, err := c.roundTrip(cmd...); err != nil {
+			conn.Close()
+			c.conn = nil
+			return fmt.Errorf("redis %s: %s: %w", c.opts.Addr, cmd[0], err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) roundTrip(args ...string) (interface{}, error) {
+	if c.opts.Timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.opts.Timeout))
+	}
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(a))+"\r\n"...)
+		buf = append(buf, a...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// Error is an error reply from the server.
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// readReply reads one RESP2 reply: simple strings, errors, integers, bulk
+// strings (nil for a missing value) and arrays.
+func (c *Client) readReply() (interface{}, error) {
+	line, err := c.rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, rest := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, Error(rest)
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(c.rd, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+}
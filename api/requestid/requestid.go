@@ -0,0 +1,101 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// Header carries the request ID in HTTP requests and responses.
+	Header = "X-Request-ID"
+	// MetadataKey carries the request ID in outgoing gRPC metadata.
+	MetadataKey = "x-request-id"
+	// Key is the gin context key holding the request ID.
+	Key = "request_id"
+
+	maxLength = 128
+)
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "".
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// Middleware takes the request ID from the X-Request-ID header, or generates
+// one when the header is missing or not a plain token. The ID is stored in
+// the gin and request contexts, echoed in the response header and recorded
+// on the request's trace span.
+func Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(Header)
+		if !valid(id) {
+			id = newID()
+		}
+		ctx.Set(Key, id)
+		ctx.Request = ctx.Request.WithContext(NewContext(ctx.Request.Context(), id))
+		ctx.Header(Header, id)
+		trace.SpanFromContext(ctx.Request.Context()).SetAttributes(attribute.String("request.id", id))
+		ctx.Next()
+	}
+}
+
+// UnaryClientInterceptor forwards the request ID of the call's context to
+// the backend as x-request-id metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := FromContext(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streaming calls.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if id := FromContext(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// valid accepts IDs of up to 128 letters, digits and "-_.:" so that client
+// supplied values cannot inject anything into logs or headers.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,205 @@
+package saga
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	eventBegin       = "begin"
+	eventStarted     = "started"
+	eventDone        = "done"
+	eventFailed      = "failed"
+	eventCompensated = "compensated"
+	eventCompleted   = "completed"
+	eventAborted     = "aborted"
+)
+
+// event is one line of the journal.
+type event struct {
+	SagaID  string          `json:"saga_id"`
+	Event   string          `json:"event"`
+	Kind    string          `json:"kind,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Step    int             `json:"step"`
+	Time    time.Time       `json:"time"`
+}
+
+// state is what the journal knows about a saga that has not finished.
+type state struct {
+	id          string
+	kind        string
+	payload     json.RawMessage
+	done        []int
+	started     int
+	compensated map[int]bool
+}
+
+// Journal is an append-only JSON-lines log of saga progress, used to finish
+// sagas that were interrupted by a gateway crash.
+type Journal struct {
+	path string
+
+	mu      sync.Mutex
+	file    *os.File
+	pending map[string]*state
+}
+
+// OpenJournal opens the journal at path, creating it if needed, and loads
+// the sagas that never completed.
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path, pending: map[string]*state{}}
+	if err := j.load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var err error
+	j.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *Journal) load() error {
+	f, err := os.Open(j.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for {
+		var e event
+		if err := dec.Decode(&e); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("saga journal %s: %w", j.path, err)
+		}
+		j.apply(&e)
+	}
+}
+
+func (j *Journal) apply(e *event) {
+	s := j.pending[e.SagaID]
+	switch e.Event {
+	case eventBegin:
+		j.pending[e.SagaID] = &state{
+			id:          e.SagaID,
+			kind:        e.Kind,
+			payload:     e.Payload,
+			started:     -1,
+			compensated: map[int]bool{},
+		}
+	case eventStarted:
+		if s != nil {
+			s.started = e.Step
+		}
+	case eventDone:
+		if s != nil {
+			s.done = append(s.done, e.Step)
+		}
+	case eventFailed:
+		if s != nil {
+			s.started = -1
+		}
+	case eventCompensated:
+		if s != nil {
+			s.compensated[e.Step] = true
+		}
+	case eventCompleted, eventAborted:
+		delete(j.pending, e.SagaID)
+	}
+}
+
+func (j *Journal) write(e event) error {
+	e.Time = time.Now().UTC()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return errors.New("saga journal is closed")
+	}
+	// Memory only follows what reached the file, so a failed write cannot
+	// make Recover act on progress the journal does not hold.
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	j.apply(&e)
+	return nil
+}
+
+// unfinished returns the sagas that were interrupted before finishing.
+func (j *Journal) unfinished() []*state {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	res := make([]*state, 0, len(j.pending))
+	for _, s := range j.pending {
+		res = append(res, s)
+	}
+	return res
+}
+
+// compact rewrites the journal so it only holds the unfinished sagas.
+func (j *Journal) compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	now := time.Now().UTC()
+	for _, s := range j.pending {
+		events := []event{{SagaID: s.id, Event: eventBegin, Kind: s.kind, Payload: s.payload, Time: now}}
+		for _, step := range s.done {
+			events = append(events, event{SagaID: s.id, Event: eventDone, Step: step, Time: now})
+		}
+		for step := range s.compensated {
+			events = append(events, event{SagaID: s.id, Event: eventCompensated, Step: step, Time: now})
+		}
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				f.Close()
+				return err
+			}
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	j.file, err = os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	return err
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
@@ -0,0 +1,218 @@
+package saga
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Salikhov079/military/api/requestid"
+)
+
+// stepTimeout and compensateTimeout bound each action and compensating call.
+// Both run on a context detached from the request's cancellation, so that a
+// client that disconnects cannot cancel a call the backend may already have
+// applied, but keeps its values such as the request ID.
+const (
+	stepTimeout       = 10 * time.Second
+	compensateTimeout = 10 * time.Second
+)
+
+// Step is one action of a saga and the call that undoes it. Compensate may
+// be nil for the last step, which has nothing after it that can fail.
+type Step struct {
+	Name       string
+	Action     func(ctx context.Context) error
+	Compensate func(ctx context.Context) error
+}
+
+// Definition builds the steps of a saga kind from its journaled payload. The
+// same definition is used to run a saga and to recover it after a crash.
+type Definition func(payload json.RawMessage) ([]Step, error)
+
+// Error is returned by Run when a step fails.
+type Error struct {
+	Step string
+	Err  error
+	// CompensationErr is set when undoing the finished steps failed too and
+	// the saga is left in the journal to be retried on restart.
+	CompensationErr error
+}
+
+func (e *Error) Error() string {
+	if e.CompensationErr != nil {
+		return fmt.Sprintf("%s: %v (compensation failed: %v)", e.Step, e.Err, e.CompensationErr)
+	}
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Coordinator runs sagas and records their progress in a journal.
+type Coordinator struct {
+	journal *Journal
+
+	mu   sync.RWMutex
+	defs map[string]Definition
+}
+
+func New(j *Journal) *Coordinator {
+	return &Coordinator{journal: j, defs: map[string]Definition{}}
+}
+
+// Register makes a saga kind available to Run and Recover.
+func (c *Coordinator) Register(kind string, def Definition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defs[kind] = def
+}
+
+// Run executes the steps of kind in order. When a step fails, the steps
+// that already finished are compensated in reverse order.
+func (c *Coordinator) Run(ctx context.Context, kind string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	steps, err := c.steps(kind, data)
+	if err != nil {
+		return err
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	if err := c.journal.write(event{SagaID: id, Event: eventBegin, Kind: kind, Payload: data}); err != nil {
+		return err
+	}
+	for i, step := range steps {
+		if err := c.journal.write(event{SagaID: id, Event: eventStarted, Step: i}); err != nil {
+			return c.abort(ctx, id, steps, i, step.Name, err)
+		}
+		sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stepTimeout)
+		err := step.Action(sctx)
+		cancel()
+		if err != nil {
+			return c.abort(ctx, id, steps, i, step.Name, err)
+		}
+		if err := c.journal.write(event{SagaID: id, Event: eventDone, Step: i}); err != nil {
+			logJournalError(ctx, err)
+		}
+	}
+	return c.journal.write(event{SagaID: id, Event: eventCompleted})
+}
+
+// abort compensates steps [0, failed) and finishes the saga.
+func (c *Coordinator) abort(ctx context.Context, id string, steps []Step, failed int, name string, cause error) error {
+	done := make([]int, failed)
+	for i := range done {
+		done[i] = i
+	}
+	if err := c.journal.write(event{SagaID: id, Event: eventFailed, Step: failed}); err != nil {
+		logJournalError(ctx, err)
+	}
+	sErr := &Error{Step: name, Err: cause}
+	if err := c.compensate(ctx, id, steps, done, nil); err != nil {
+		sErr.CompensationErr = err
+		return sErr
+	}
+	if err := c.journal.write(event{SagaID: id, Event: eventAborted}); err != nil {
+		logJournalError(ctx, err)
+	}
+	return sErr
+}
+
+// compensate undoes the given steps in reverse order, skipping the ones
+// already compensated. It stops at the first failure.
+func (c *Coordinator) compensate(parent context.Context, id string, steps []Step, done []int, compensated map[int]bool) error {
+	sort.Sort(sort.Reverse(sort.IntSlice(done)))
+	for _, i := range done {
+		if compensated[i] || i >= len(steps) || steps[i].Compensate == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), compensateTimeout)
+		err := steps[i].Compensate(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("compensate %s: %w", steps[i].Name, err)
+		}
+		if err := c.journal.write(event{SagaID: id, Event: eventCompensated, Step: i}); err != nil {
+			logJournalError(parent, err)
+		}
+	}
+	return nil
+}
+
+// Recover finishes the sagas that a previous run left unfinished, then
+// compacts the journal. A saga whose last step is journaled as done
+// succeeded and is completed; the others are compensated. A step that was
+// started but never confirmed cannot be undone safely and is logged for
+// manual reconciliation.
+func (c *Coordinator) Recover() error {
+	for _, s := range c.journal.unfinished() {
+		steps, err := c.steps(s.kind, s.payload)
+		if err != nil {
+			slog.Error("Error while recovering saga", "saga_id", s.id, "kind", s.kind, "error", err)
+			continue
+		}
+		if len(steps) > 0 && contains(s.done, len(steps)-1) {
+			if err := c.journal.write(event{SagaID: s.id, Event: eventCompleted}); err != nil {
+				return err
+			}
+			slog.Info("Saga was recovered and completed", "saga_id", s.id, "kind", s.kind)
+			continue
+		}
+		if s.started >= 0 && !contains(s.done, s.started) && s.started < len(steps) {
+			slog.Warn("Saga was interrupted during a step, its outcome is unknown and must be checked manually",
+				"saga_id", s.id, "kind", s.kind, "step", steps[s.started].Name)
+		}
+		if err := c.compensate(context.Background(), s.id, steps, s.done, s.compensated); err != nil {
+			slog.Error("Error while recovering saga", "saga_id", s.id, "kind", s.kind, "error", err)
+			continue
+		}
+		if err := c.journal.write(event{SagaID: s.id, Event: eventAborted}); err != nil {
+			return err
+		}
+		slog.Info("Saga was recovered and compensated", "saga_id", s.id, "kind", s.kind)
+	}
+	return c.journal.compact()
+}
+
+func (c *Coordinator) steps(kind string, payload json.RawMessage) ([]Step, error) {
+	c.mu.RLock()
+	def, ok := c.defs[kind]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown saga kind %q", kind)
+	}
+	return def(payload)
+}
+
+func contains(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func logJournalError(ctx context.Context, err error) {
+	slog.ErrorContext(ctx, "Error while writing saga journal", "request_id", requestid.FromContext(ctx), "error", err)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,169 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// recorder is a saga kind of three steps whose calls are recorded; fail
+// names the step whose action fails.
+type recorder struct {
+	fail  string
+	calls []string
+}
+
+func (r *recorder) define(json.RawMessage) ([]Step, error) {
+	var steps []Step
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		steps = append(steps, Step{
+			Name: name,
+			Action: func(context.Context) error {
+				r.calls = append(r.calls, "do "+name)
+				if name == r.fail {
+					return errors.New("failed")
+				}
+				return nil
+			},
+			Compensate: func(context.Context) error {
+				r.calls = append(r.calls, "undo "+name)
+				return nil
+			},
+		})
+	}
+	return steps, nil
+}
+
+func open(t *testing.T, path string) *Journal {
+	t.Helper()
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		fail  string
+		calls []string
+	}{
+		{"success", "", []string{"do a", "do b", "do c"}},
+		{"failure", "c", []string{"do a", "do b", "do c", "undo b", "undo a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := open(t, filepath.Join(t.TempDir(), "journal.jsonl"))
+			c := New(j)
+			r := &recorder{fail: tt.fail}
+			c.Register("test", r.define)
+
+			err := c.Run(context.Background(), "test", nil)
+			var sErr *Error
+			if tt.fail == "" && err != nil || tt.fail != "" && !(errors.As(err, &sErr) && sErr.Step == tt.fail) {
+				t.Fatalf("Run() = %v", err)
+			}
+			if !reflect.DeepEqual(r.calls, tt.calls) {
+				t.Errorf("calls = %v, want %v", r.calls, tt.calls)
+			}
+			if n := len(j.unfinished()); n != 0 {
+				t.Errorf("%d sagas left unfinished", n)
+			}
+		})
+	}
+}
+
+func TestRunOutlivesRequest(t *testing.T) {
+	c := New(open(t, filepath.Join(t.TempDir(), "journal.jsonl")))
+	ctx, cancel := context.WithCancel(context.Background())
+	var errs []error
+	c.Register("test", func(json.RawMessage) ([]Step, error) {
+		action := func(ctx context.Context) error {
+			// The client disconnects during the first step.
+			cancel()
+			errs = append(errs, ctx.Err())
+			return nil
+		}
+		return []Step{{Name: "a", Action: action}, {Name: "b", Action: action}}, nil
+	})
+
+	if err := c.Run(ctx, "test", nil); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if len(errs) != 2 || errs[0] != nil || errs[1] != nil {
+		t.Errorf("step contexts were canceled: %v", errs)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name  string
+		done  []int
+		calls []string
+	}{
+		{"all steps done", []int{0, 1, 2}, nil},
+		{"interrupted", []int{0, 1}, []string{"undo b", "undo a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			j := open(t, path)
+			events := []event{{SagaID: "s1", Event: eventBegin, Kind: "test"}}
+			for _, step := range tt.done {
+				events = append(events,
+					event{SagaID: "s1", Event: eventStarted, Step: step},
+					event{SagaID: "s1", Event: eventDone, Step: step})
+			}
+			for _, e := range events {
+				if err := j.write(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+			j.Close()
+
+			j = open(t, path)
+			c := New(j)
+			r := &recorder{}
+			c.Register("test", r.define)
+			if err := c.Recover(); err != nil {
+				t.Fatalf("Recover() = %v", err)
+			}
+			if !reflect.DeepEqual(r.calls, tt.calls) {
+				t.Errorf("calls = %v, want %v", r.calls, tt.calls)
+			}
+			if n := len(j.unfinished()); n != 0 {
+				t.Errorf("%d sagas left unfinished", n)
+			}
+		})
+	}
+}
+
+func TestWriteFailureKeepsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := open(t, path)
+	if err := j.write(event{SagaID: "s1", Event: eventBegin, Kind: "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file opened read-only fails every write.
+	ro, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.file.Close()
+	j.file = ro
+
+	if err := j.write(event{SagaID: "s1", Event: eventCompleted}); err == nil {
+		t.Fatal("write() to a read-only file succeeded")
+	}
+	if n := len(j.unfinished()); n != 1 {
+		t.Errorf("%d sagas unfinished after a failed write, want 1", n)
+	}
+}
@@ -0,0 +1,253 @@
+// Package stream keeps the events of running AI chat streams so that a
+// client that lost its connection can resume from the last event it saw.
+package stream
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	Chunk     = "chunk"
+	Tool      = "tool"
+	Done      = "done"
+	Error     = "error"
+	Heartbeat = "heartbeat"
+)
+
+// Event is one frame of a stream. Seq counts from 1 within the stream.
+type Event struct {
+	Seq  int
+	Type string
+	Data json.RawMessage
+}
+
+// ID returns the event ID clients send back in Last-Event-ID.
+func (e Event) ID(streamID string) string {
+	return streamID + ":" + strconv.Itoa(e.Seq)
+}
+
+// ParseEventID splits an event ID into the stream ID and the sequence
+// number of the event.
+func ParseEventID(id string) (streamID string, seq int, err error) {
+	streamID, n, ok := strings.Cut(id, ":")
+	if ok {
+		seq, err = strconv.Atoi(n)
+	}
+	if !ok || err != nil || streamID == "" || seq < 0 {
+		return "", 0, fmt.Errorf("invalid event ID %q", id)
+	}
+	return streamID, seq, nil
+}
+
+// Emit appends an event to the stream that is being produced.
+type Emit func(typ string, v interface{})
+
+// Hub runs streams and keeps them for the resume window: while nobody reads
+// a running stream, and after a stream has finished.
+type Hub struct {
+	// Heartbeat is how often readers send a frame to idle clients.
+	Heartbeat time.Duration
+
+	window  time.Duration
+	mu      sync.Mutex
+	streams map[string]*Stream
+	closing bool
+	// active counts running producers and attached readers; drained is
+	// closed when it drops to zero during Shutdown.
+	active  int
+	drained chan struct{}
+}
+
+// NewHub returns a hub that keeps unread streams for window.
+func NewHub(window, heartbeat time.Duration) *Hub {
+	return &Hub{Heartbeat: heartbeat, window: window, streams: map[string]*Stream{}}
+}
+
+// Start runs produce in its own goroutine and returns its stream. produce
+// gets a context that carries the values of parent but is canceled only by
+// Cancel, Shutdown, or when no reader has been attached for the resume
+// window.
+func (h *Hub) Start(parent context.Context, owner string, produce func(ctx context.Context, emit Emit)) *Stream {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	s := &Stream{
+		ID:      newID(),
+		Owner:   owner,
+		hub:     h,
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+	h.mu.Lock()
+	h.streams[s.ID] = s
+	h.active++
+	if h.closing {
+		cancel()
+	}
+	h.mu.Unlock()
+
+	go func() {
+		defer h.release()
+		defer cancel()
+		produce(ctx, s.emit)
+		s.finish()
+	}()
+	return s
+}
+
+// Get returns the stream with id when it belongs to owner.
+func (h *Hub) Get(id, owner string) (*Stream, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.streams[id]
+	if !ok || s.Owner != owner {
+		return nil, false
+	}
+	return s, true
+}
+
+// Shutdown cancels every running stream and waits until their producers and
+// readers have returned or ctx is done. Streams started afterwards are
+// canceled at once. Readers of hijacked connections are not seen by
+// http.Server.Shutdown, so it must be called before it.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	for _, s := range h.streams {
+		s.cancel()
+	}
+	if h.active == 0 {
+		h.mu.Unlock()
+		return nil
+	}
+	if h.drained == nil {
+		h.drained = make(chan struct{})
+	}
+	drained := h.drained
+	h.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Hub) acquire() {
+	h.mu.Lock()
+	h.active++
+	h.mu.Unlock()
+}
+
+func (h *Hub) release() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.active--
+	if h.active == 0 && h.drained != nil {
+		close(h.drained)
+		h.drained = nil
+	}
+}
+
+func (h *Hub) remove(id string) {
+	h.mu.Lock()
+	delete(h.streams, id)
+	h.mu.Unlock()
+}
+
+// Stream is a running or finished stream.
+type Stream struct {
+	ID    string
+	Owner string
+
+	hub    *Hub
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	events   []Event
+	finished bool
+	changed  chan struct{}
+	readers  int
+	idle     *time.Timer
+}
+
+// Events returns the events after seq, a channel that is closed when more
+// are added and whether the stream has finished.
+func (s *Stream) Events(after int) ([]Event, <-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if after > len(s.events) {
+		after = len(s.events)
+	}
+	return s.events[after:], s.changed, s.finished
+}
+
+// Attach registers a reader. Every Attach must be followed by a Detach.
+func (s *Stream) Attach() {
+	s.hub.acquire()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readers++
+	if s.idle != nil {
+		s.idle.Stop()
+		s.idle = nil
+	}
+}
+
+// Detach unregisters a reader. When the last reader of a running stream
+// leaves, the stream is canceled unless a reader attaches within the resume
+// window.
+func (s *Stream) Detach() {
+	s.mu.Lock()
+	s.readers--
+	if s.readers == 0 && !s.finished {
+		s.idle = time.AfterFunc(s.hub.window, s.cancel)
+	}
+	s.mu.Unlock()
+	s.hub.release()
+}
+
+// Cancel cancels the producer of the stream.
+func (s *Stream) Cancel() {
+	s.cancel()
+}
+
+func (s *Stream) emit(typ string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"message": err.Error()})
+		typ = Error
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, Event{Seq: len(s.events) + 1, Type: typ, Data: data})
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Stream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished = true
+	close(s.changed)
+	s.changed = make(chan struct{})
+	if s.idle != nil {
+		s.idle.Stop()
+		s.idle = nil
+	}
+	time.AfterFunc(s.hub.window, func() { s.hub.remove(s.ID) })
+}
+
+func newID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,52 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	h := NewHub(time.Minute, time.Second)
+	s := h.Start(context.Background(), "u1", func(ctx context.Context, emit Emit) {
+		<-ctx.Done()
+		emit(Error, map[string]string{"message": ctx.Err().Error()})
+	})
+	s.Attach()
+	detached := make(chan struct{})
+	go func() {
+		defer close(detached)
+		defer s.Detach()
+		for {
+			_, changed, finished := s.Events(0)
+			if finished {
+				return
+			}
+			<-changed
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	select {
+	case <-detached:
+	case <-time.After(time.Second):
+		t.Error("reader is still attached after Shutdown()")
+	}
+	if events, _, finished := s.Events(0); !finished || len(events) != 1 || events[0].Type != Error {
+		t.Errorf("stream after Shutdown = %v, finished %v", events, finished)
+	}
+
+	late := h.Start(context.Background(), "u1", func(ctx context.Context, emit Emit) {
+		<-ctx.Done()
+	})
+	if err := h.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() of a stream started late = %v", err)
+	}
+	if _, _, finished := late.Events(0); !finished {
+		t.Error("stream started after Shutdown is still running")
+	}
+}
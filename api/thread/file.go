@@ -0,0 +1,122 @@
+package thread
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// record is one line of the file: a thread as created or renamed, a
+// deletion or a message.
+type record struct {
+	Op      string   `json:"op"`
+	Owner   string   `json:"owner"`
+	ID      string   `json:"id"`
+	Thread  *Thread  `json:"thread,omitempty"`
+	Message *Message `json:"message,omitempty"`
+}
+
+const (
+	opThread  = "thread"
+	opDelete  = "delete"
+	opMessage = "message"
+)
+
+// fileStore is a memory store whose changes are first appended to a
+// JSON-lines file, so threads survive a gateway restart.
+type fileStore struct {
+	*memoryStore
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileStore opens a store backed by the file at path and bounded by l.
+// The file is compacted when it is opened, which also drops the messages
+// over the limit.
+func NewFileStore(path string, l Limits) (Store, error) {
+	s := &fileStore{memoryStore: newMemoryStore(l)}
+	if err := s.load(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := s.rewrite(path); err != nil {
+		return nil, err
+	}
+
+	var err error
+	s.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s.persist = s.write
+	return s, nil
+}
+
+// Close syncs and closes the file.
+func (s *fileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+func (s *fileStore) write(r *record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *fileStore) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			return err
+		}
+		s.apply(&r)
+	}
+	return nil
+}
+
+func (s *fileStore) rewrite(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range s.entries {
+		t := e.thread
+		records := []*record{{Op: opThread, Owner: t.Owner, ID: t.ID, Thread: &t}}
+		for i := range e.messages {
+			records = append(records, &record{Op: opMessage, Owner: t.Owner, ID: t.ID, Message: &e.messages[i]})
+		}
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				f.Close()
+				return err
+			}
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
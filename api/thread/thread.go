@@ -0,0 +1,251 @@
+// Package thread keeps the AI conversation threads of each user and the
+// messages exchanged in them.
+package thread
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default is the ID of the thread that chat messages without a thread go
+// to. It is created on first use.
+const Default = "default"
+
+var (
+	ErrNotFound = errors.New("thread not found")
+	ErrExists   = errors.New("thread already exists")
+	ErrLimit    = errors.New("thread limit reached, delete a thread first")
+)
+
+// Limits bound what a store keeps. Zero values do not limit.
+type Limits struct {
+	// Threads is the number of threads a user may have besides the default
+	// thread.
+	Threads int
+	// Messages is the number of messages kept per thread; older ones are
+	// dropped.
+	Messages int
+}
+
+// Thread is one conversation of a user.
+type Thread struct {
+	ID        string    `json:"id"`
+	Owner     string    `json:"owner"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Messages  int       `json:"messages"`
+}
+
+// Message is one question and the AI's answer to it.
+type Message struct {
+	Time         time.Time `json:"time"`
+	RequestText  string    `json:"request_text"`
+	ResponseText string    `json:"response_text"`
+}
+
+// Store keeps threads and their messages. Threads are addressed by owner
+// and ID, so a user can never reach the thread of another.
+type Store interface {
+	// Create stores t, generating its ID when empty.
+	Create(t *Thread) error
+	// Get returns a thread.
+	Get(owner, id string) (*Thread, error)
+	// List returns the threads of owner, the most recently updated first.
+	List(owner string) ([]*Thread, error)
+	// Rename changes the title of a thread.
+	Rename(owner, id, title string) (*Thread, error)
+	// Delete removes a thread and its messages.
+	Delete(owner, id string) error
+	// Append adds m to a thread.
+	Append(owner, id string, m Message) error
+	// Messages returns the messages of a thread sent between from and to,
+	// oldest first. Zero times do not limit.
+	Messages(owner, id string, from, to time.Time) ([]Message, error)
+}
+
+type entry struct {
+	thread   Thread
+	messages []Message
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	// owned counts the threads of each owner, the default thread aside.
+	owned  map[string]int
+	limits Limits
+	// persist, when set, stores a change before it is applied, so memory
+	// never holds a change that was not stored.
+	persist func(r *record) error
+}
+
+// NewMemoryStore returns a process-local store bounded by l.
+func NewMemoryStore(l Limits) Store {
+	return newMemoryStore(l)
+}
+
+func newMemoryStore(l Limits) *memoryStore {
+	return &memoryStore{entries: map[string]*entry{}, owned: map[string]int{}, limits: l}
+}
+
+func key(owner, id string) string {
+	return owner + "\x00" + id
+}
+
+func (s *memoryStore) Create(t *Thread) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.ID == "" {
+		t.ID = newID()
+	}
+	if _, ok := s.entries[key(t.Owner, t.ID)]; ok {
+		return ErrExists
+	}
+	if t.ID != Default && s.limits.Threads > 0 && s.owned[t.Owner] >= s.limits.Threads {
+		return ErrLimit
+	}
+	now := time.Now().UTC()
+	t.CreatedAt, t.UpdatedAt, t.Messages = now, now, 0
+	c := *t
+	return s.commit(&record{Op: opThread, Owner: t.Owner, ID: t.ID, Thread: &c})
+}
+
+func (s *memoryStore) Get(owner, id string) (*Thread, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key(owner, id)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t := e.thread
+	return &t, nil
+}
+
+func (s *memoryStore) List(owner string) ([]*Thread, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := []*Thread{}
+	for _, e := range s.entries {
+		if e.thread.Owner == owner {
+			t := e.thread
+			res = append(res, &t)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].UpdatedAt.After(res[j].UpdatedAt) })
+	return res, nil
+}
+
+func (s *memoryStore) Rename(owner, id, title string) (*Thread, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key(owner, id)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t := e.thread
+	t.Title = title
+	t.UpdatedAt = time.Now().UTC()
+	if err := s.commit(&record{Op: opThread, Owner: owner, ID: id, Thread: &t}); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *memoryStore) Delete(owner, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key(owner, id)]; !ok {
+		return ErrNotFound
+	}
+	return s.commit(&record{Op: opDelete, Owner: owner, ID: id})
+}
+
+func (s *memoryStore) Append(owner, id string, m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key(owner, id)]; !ok {
+		return ErrNotFound
+	}
+	return s.commit(&record{Op: opMessage, Owner: owner, ID: id, Message: &m})
+}
+
+func (s *memoryStore) Messages(owner, id string, from, to time.Time) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key(owner, id)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	res := []Message{}
+	for _, m := range e.messages {
+		if (from.IsZero() || !m.Time.Before(from)) && (to.IsZero() || !m.Time.After(to)) {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+// commit persists r and then applies it. s.mu must be held.
+func (s *memoryStore) commit(r *record) error {
+	if s.persist != nil {
+		if err := s.persist(r); err != nil {
+			return err
+		}
+	}
+	s.apply(r)
+	return nil
+}
+
+// apply changes memory as r says. s.mu must be held or s not yet shared.
+func (s *memoryStore) apply(r *record) {
+	k := key(r.Owner, r.ID)
+	switch r.Op {
+	case opThread:
+		if e, ok := s.entries[k]; ok {
+			e.thread = *r.Thread
+		} else {
+			s.entries[k] = &entry{thread: *r.Thread}
+			if r.ID != Default {
+				s.owned[r.Owner]++
+			}
+		}
+	case opDelete:
+		if _, ok := s.entries[k]; ok && r.ID != Default {
+			s.owned[r.Owner]--
+			if s.owned[r.Owner] == 0 {
+				delete(s.owned, r.Owner)
+			}
+		}
+		delete(s.entries, k)
+	case opMessage:
+		if e, ok := s.entries[k]; ok {
+			e.messages = append(e.messages, *r.Message)
+			if max := s.limits.Messages; max > 0 && len(e.messages) > max {
+				// Shift in place so the backing array stays bounded too.
+				copy(e.messages, e.messages[len(e.messages)-max:])
+				clear(e.messages[max:])
+				e.messages = e.messages[:max]
+			}
+			e.thread.Messages = len(e.messages)
+			e.thread.UpdatedAt = r.Message.Time
+		}
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,157 @@
+package thread
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func appendN(t *testing.T, s Store, owner, id string, n int) {
+	t.Helper()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		m := Message{Time: start.Add(time.Duration(i) * time.Minute), RequestText: string(rune('a' + i))}
+		if err := s.Append(owner, id, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func texts(t *testing.T, s Store, owner, id string) string {
+	t.Helper()
+	messages, err := s.Messages(owner, id, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res string
+	for _, m := range messages {
+		res += m.RequestText
+	}
+	return res
+}
+
+func TestMemoryStoreMaxMessages(t *testing.T) {
+	s := NewMemoryStore(Limits{Messages: 2})
+	if err := s.Create(&Thread{ID: "t1", Owner: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	appendN(t, s, "u1", "t1", 5)
+
+	if got := texts(t, s, "u1", "t1"); got != "de" {
+		t.Errorf("messages = %q, want the newest two", got)
+	}
+	if th, _ := s.Get("u1", "t1"); th.Messages != 2 {
+		t.Errorf("Messages = %d, want 2", th.Messages)
+	}
+	if _, err := s.Messages("u2", "t1", time.Time{}, time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("another owner got %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "threads.jsonl")
+	s, err := NewFileStore(path, Limits{Messages: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"t1", "t2"} {
+		if err := s.Create(&Thread{ID: id, Owner: "u1", Title: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appendN(t, s, "u1", "t1", 3)
+	if _, err := s.Rename("u1", "t1", "renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("u1", "t2"); err != nil {
+		t.Fatal(err)
+	}
+	s.(*fileStore).Close()
+
+	s, err = NewFileStore(path, Limits{Messages: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.(*fileStore).Close()
+	if th, err := s.Get("u1", "t1"); err != nil || th.Title != "renamed" || th.Messages != 2 {
+		t.Errorf("t1 = %+v, %v", th, err)
+	}
+	if got := texts(t, s, "u1", "t1"); got != "bc" {
+		t.Errorf("messages = %q, want the newest two", got)
+	}
+	if _, err := s.Get("u1", "t2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleted thread: %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileStoreWriteFailureKeepsState(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(filepath.Join(dir, "threads.jsonl"), Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(&Thread{ID: "t1", Owner: "u1", Title: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := s.(*fileStore)
+	fs.file.Close()
+	readOnly := filepath.Join(dir, "read-only")
+	if err := os.WriteFile(readOnly, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if fs.file, err = os.Open(readOnly); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.file.Close()
+
+	if err := s.Create(&Thread{ID: "t2", Owner: "u1"}); err == nil {
+		t.Error("Create succeeded without writing")
+	}
+	if _, err := s.Rename("u1", "t1", "new"); err == nil {
+		t.Error("Rename succeeded without writing")
+	}
+	if err := s.Append("u1", "t1", Message{RequestText: "a"}); err == nil {
+		t.Error("Append succeeded without writing")
+	}
+	if err := s.Delete("u1", "t1"); err == nil {
+		t.Error("Delete succeeded without writing")
+	}
+
+	if _, err := s.Get("u1", "t2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("t2 was created in memory: %v", err)
+	}
+	if th, err := s.Get("u1", "t1"); err != nil || th.Title != "old" || th.Messages != 0 {
+		t.Errorf("t1 = %+v, %v; want it unchanged", th, err)
+	}
+}
+
+func TestMemoryStoreMaxThreads(t *testing.T) {
+	s := NewMemoryStore(Limits{Threads: 1})
+	if err := s.Create(&Thread{Owner: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(&Thread{Owner: "u1"}); !errors.Is(err, ErrLimit) {
+		t.Errorf("second thread: %v, want ErrLimit", err)
+	}
+	if err := s.Create(&Thread{ID: Default, Owner: "u1"}); err != nil {
+		t.Errorf("default thread: %v, want it exempt from the limit", err)
+	}
+	if err := s.Create(&Thread{Owner: "u2"}); err != nil {
+		t.Errorf("another owner: %v", err)
+	}
+
+	threads, _ := s.List("u1")
+	for _, th := range threads {
+		if th.ID != Default {
+			if err := s.Delete("u1", th.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := s.Create(&Thread{Owner: "u1"}); err != nil {
+		t.Errorf("after Delete: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// ErrUnknownUser is returned by CredentialStore.Lookup for users that no
+// longer exist.
+var ErrUnknownUser = errors.New("user no longer exists")
+
+// CredentialStore verifies login credentials and resolves who they belong to.
+type CredentialStore interface {
+	Authenticate(ctx context.Context, email, password string) (*Principal, error)
+	// Lookup resolves the current principal of email without a password,
+	// so that refreshed tokens carry the user's current role.
+	Lookup(ctx context.Context, email string) (*Principal, error)
+}
+
+// Credential is a password hash entry from the credentials file.
+type Credential struct {
+	Email        string `yaml:"email"`
+	PasswordHash string `yaml:"password_hash"`
+	Role         string `yaml:"role"`
+}
+
+// Passwords holds bcrypt password hashes keyed by lower-cased email.
+type Passwords map[string]Credential
+
+// LoadPasswords reads a YAML credentials file. A missing file yields an empty
+// set, which lets nobody log in.
+func LoadPasswords(path string) (Passwords, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Passwords{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Credentials []Credential `yaml:"credentials"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	p := make(Passwords, len(file.Credentials))
+	for _, c := range file.Credentials {
+		p[strings.ToLower(c.Email)] = c
+	}
+	return p, nil
+}
+
+// Get returns the credential entry of email.
+func (p Passwords) Get(email string) (Credential, bool) {
+	c, ok := p[strings.ToLower(email)]
+	return c, ok
+}
+
+// Check verifies password for email and returns its credential entry.
+func (p Passwords) Check(email, password string) (Credential, error) {
+	c, ok := p.Get(email)
+	if !ok {
+		return Credential{}, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(c.PasswordHash), []byte(password)); err != nil {
+		return Credential{}, ErrInvalidCredentials
+	}
+	return c, nil
+}
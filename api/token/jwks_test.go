@@ -0,0 +1,96 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Salikhov079/military/config"
+)
+
+// useRSA makes the package sign with a fresh RSA key published under kid in
+// a JWKS file and accept only algs, until the test ends.
+func useRSA(t *testing.T, kid string, algs string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "key.pem")
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	doc, _ := json.Marshal(map[string]interface{}{"keys": []jwk{{
+		Kid: kid,
+		Kty: "RSA",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	jwksPath := filepath.Join(dir, "jwks.json")
+	if err := os.WriteFile(jwksPath, doc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldKeys, oldSigner, oldAlgs := keys, signer, allowedAlgs
+	t.Cleanup(func() { keys, signer, allowedAlgs = oldKeys, oldSigner, oldAlgs })
+	ks, err := LoadKeySet(jwksPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetKeySet(ks)
+	if err := LoadSigningKey(keyPath, kid); err != nil {
+		t.Fatal(err)
+	}
+	allowedAlgs = allowList(algs)
+}
+
+func TestAsymmetricTokens(t *testing.T) {
+	p := &Principal{ID: "c1", Email: "c1@army", Role: "commander"}
+	hmac := issue(t, p)
+
+	useRSA(t, "k1", "RS256,ES256")
+	rsaTokens := issue(t, p)
+	if _, err := ExtractClaim(rsaTokens.AccessToken); err != nil {
+		t.Fatalf("RS256 token: %v", err)
+	}
+	if _, err := ExtractClaim(hmac.AccessToken); err == nil {
+		t.Fatal("HS256 token was accepted although only RS256 and ES256 are allowed")
+	}
+
+	// A rotated key set without the old kid rejects the old tokens.
+	useRSA(t, "k2", "RS256")
+	if _, err := ExtractClaim(rsaTokens.AccessToken); err == nil {
+		t.Fatal("token signed with a retired key was accepted")
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	oldKey, oldAlgs := tokenKey, allowedAlgs
+	t.Cleanup(func() { tokenKey, allowedAlgs = oldKey, oldAlgs })
+
+	tests := []struct {
+		key, algs string
+		ok        bool
+	}{
+		{config.DefaultTokenKey, "HS256", false},
+		{config.DefaultTokenKey, "RS256,HS512", false},
+		{config.DefaultTokenKey, "RS256,ES256", true},
+		{"a-real-secret", "HS256", true},
+	}
+	for _, tt := range tests {
+		tokenKey, allowedAlgs = tt.key, allowList(tt.algs)
+		if err := CheckConfig(); (err == nil) != tt.ok {
+			t.Errorf("CheckConfig with key %q and algorithms %s: err = %v", tt.key, tt.algs, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package token
+
+import (
+	"sync"
+	"time"
+)
+
+// RevocationList records revoked token IDs (jti) until the tokens expire.
+type RevocationList interface {
+	// Revoke adds jti to the list and reports whether it was not revoked yet.
+	Revoke(jti string, until time.Time) bool
+	Revoked(jti string) bool
+}
+
+var revoked RevocationList = NewMemoryRevocationList()
+
+// SetRevocationList replaces the revocation list checked by ExtractClaim.
+func SetRevocationList(l RevocationList) {
+	revoked = l
+}
+
+type memoryRevocationList struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// NewMemoryRevocationList returns a process-local revocation list.
+func NewMemoryRevocationList() RevocationList {
+	return &memoryRevocationList{entries: map[string]time.Time{}}
+}
+
+func (l *memoryRevocationList) Revoke(jti string, until time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range l.entries {
+		if exp.Before(now) {
+			delete(l.entries, id)
+		}
+	}
+	if _, ok := l.entries[jti]; ok {
+		return false
+	}
+	l.entries[jti] = until
+	return true
+}
+
+func (l *memoryRevocationList) Revoked(jti string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	exp, ok := l.entries[jti]
+	return ok && exp.After(time.Now())
+}
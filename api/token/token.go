@@ -1,27 +1,212 @@
 package token
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/Salikhov079/military/config"
 
 	"github.com/form3tech-oss/jwt-go"
 )
 
+const (
+	accessType  = "access"
+	refreshType = "refresh"
+)
+
 type Tokens struct {
-	RefreshToken string
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// Principal is the authenticated caller tokens are issued for.
+type Principal struct {
+	ID    string
+	Email string
+	Role  string
+}
+
+// Options configures how tokens are signed and verified.
+type Options struct {
+	// Key is the HMAC secret of HS256 tokens.
+	Key string
+	// Algorithms is the comma-separated list of algorithms accepted in tokens.
+	Algorithms string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+var (
+	tokenKey    = config.DefaultTokenKey
+	allowedAlgs = allowList("HS256")
+	accessTTL   = 15 * time.Minute
+	refreshTTL  = 7 * 24 * time.Hour
+
+	keys   *KeySet
+	signer *signingKey
+)
+
+// Configure sets the token options. Until it is called, HS256 tokens are
+// signed with config.DefaultTokenKey, which CheckConfig rejects.
+func Configure(o Options) {
+	tokenKey = o.Key
+	allowedAlgs = allowList(o.Algorithms)
+	accessTTL = o.AccessTTL
+	refreshTTL = o.RefreshTTL
+}
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// SetKeySet makes ExtractClaim verify RS256/ES256 tokens against ks.
+func SetKeySet(ks *KeySet) {
+	keys = ks
+}
+
+// LoadSigningKey makes GenerateTokens sign with the PEM encoded RSA or EC
+// private key at path instead of the HMAC secret. kid must match the key's
+// entry in the JWKS document.
+func LoadSigningKey(path, kid string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		signer = &signingKey{kid: kid, method: jwt.SigningMethodRS256, key: key}
+		return nil
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return fmt.Errorf("signing key %s is neither an RSA nor an EC private key", path)
+	}
+	method := jwt.SigningMethodES256
+	switch key.Curve.Params().BitSize {
+	case 384:
+		method = jwt.SigningMethodES384
+	case 521:
+		method = jwt.SigningMethodES512
+	}
+	signer = &signingKey{kid: kid, method: method, key: key}
+	return nil
+}
+
+// GenerateTokens issues a short-lived access token and a refresh token for p.
+func GenerateTokens(p *Principal) (*Tokens, error) {
+	access, err := sign(p, accessType, accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := sign(p, refreshType, refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(accessTTL.Seconds()),
+	}, nil
+}
+
+// CheckConfig reports a configuration that lets anyone forge tokens: an
+// allowed HMAC algorithm with the public default secret.
+func CheckConfig() error {
+	for alg := range allowedAlgs {
+		if strings.HasPrefix(alg, "HS") && tokenKey == config.DefaultTokenKey {
+			return fmt.Errorf("%s is allowed but the token key is the built-in default; set TokenKey or allow only RS256/ES256", alg)
+		}
+	}
+	return nil
+}
+
+// ErrForeignToken is returned by Revoke for tokens issued to somebody else.
+var ErrForeignToken = errors.New("token was issued to another user")
+
+// Refresh rotates a refresh token: the presented token is revoked and a new
+// pair is issued, so every refresh token can be used only once. The user is
+// looked up in users again, so the new pair carries the current role and a
+// removed user cannot refresh.
+func Refresh(ctx context.Context, refreshToken string, users CredentialStore) (*Tokens, error) {
+	claims, err := parse(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims["type"] != refreshType {
+		return nil, errors.New("not a refresh token")
+	}
+	p, err := users.Lookup(ctx, stringClaim(claims, "email"))
+	if err != nil {
+		return nil, err
+	}
+	if p.ID != Subject(claims) {
+		return nil, ErrUnknownUser
+	}
+	fresh, err := revokeClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, errors.New("refresh token has already been used")
+	}
+	return GenerateTokens(p)
 }
 
-var tokenKey = config.Load().TokenKey
+// Revoke puts a token of subject on the revocation list until it expires.
+func Revoke(tokenStr, subject string) error {
+	claims, err := parse(tokenStr)
+	if err != nil {
+		return err
+	}
+	if Subject(claims) != subject {
+		return ErrForeignToken
+	}
+	_, err = revokeClaims(claims)
+	return err
+}
 
 func ExtractClaim(tokenStr string) (jwt.MapClaims, error) {
+	claims, err := parse(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims["type"] == refreshType {
+		return nil, errors.New("refresh token cannot be used for access")
+	}
+	return claims, nil
+}
+
+func parse(tokenStr string) (jwt.MapClaims, error) {
 	var (
 		token *jwt.Token
 		err   error
 	)
 
 	keyFunc := func(token *jwt.Token) (interface{}, error) {
-		return []byte(tokenKey), nil
+		alg := token.Method.Alg()
+		if !allowedAlgs[alg] {
+			return nil, fmt.Errorf("signing algorithm %s is not allowed", alg)
+		}
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			return []byte(tokenKey), nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+			if keys == nil {
+				return nil, errors.New("no key set configured")
+			}
+			kid, _ := token.Header["kid"].(string)
+			return keys.Key(kid, alg)
+		default:
+			return nil, fmt.Errorf("unsupported signing algorithm %s", alg)
+		}
 	}
 	token, err = jwt.Parse(tokenStr, keyFunc)
 	if err != nil {
@@ -33,5 +218,79 @@ func ExtractClaim(tokenStr string) (jwt.MapClaims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	if jti := stringClaim(claims, "jti"); jti != "" && revoked.Revoked(jti) {
+		return nil, errors.New("token has been revoked")
+	}
+
 	return claims, nil
 }
+
+func sign(p *Principal, typ string, ttl time.Duration) (string, error) {
+	jti, err := newID()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":   p.ID,
+		"email": p.Email,
+		"role":  p.Role,
+		"type":  typ,
+		"jti":   jti,
+		"iat":   now.Unix(),
+		"exp":   now.Add(ttl).Unix(),
+	}
+	if signer == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(tokenKey))
+	}
+	token := jwt.NewWithClaims(signer.method, claims)
+	token.Header["kid"] = signer.kid
+	return token.SignedString(signer.key)
+}
+
+func revokeClaims(claims jwt.MapClaims) (bool, error) {
+	jti := stringClaim(claims, "jti")
+	if jti == "" {
+		return false, errors.New("token has no jti claim")
+	}
+	exp, _ := claims["exp"].(float64)
+	return revoked.Revoke(jti, time.Unix(int64(exp), 0)), nil
+}
+
+func allowList(algs string) map[string]bool {
+	res := map[string]bool{}
+	for _, alg := range strings.Split(algs, ",") {
+		if alg = strings.TrimSpace(alg); alg != "" {
+			res[alg] = true
+		}
+	}
+	return res
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func stringClaim(claims jwt.MapClaims, key string) string {
+	v, _ := claims[key].(string)
+	return v
+}
+
+// Subject returns the caller ID carried by the claims.
+func Subject(claims jwt.MapClaims) string {
+	for _, key := range []string{"sub", "id", "user_id"} {
+		if v := stringClaim(claims, key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Role returns the role the claims were issued for.
+func Role(claims jwt.MapClaims) string {
+	return stringClaim(claims, "role")
+}
@@ -0,0 +1,100 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// users is a CredentialStore over a fixed set of principals keyed by email.
+type users map[string]*Principal
+
+func (u users) Authenticate(ctx context.Context, email, password string) (*Principal, error) {
+	return nil, ErrInvalidCredentials
+}
+
+func (u users) Lookup(ctx context.Context, email string) (*Principal, error) {
+	p, ok := u[email]
+	if !ok {
+		return nil, ErrUnknownUser
+	}
+	return p, nil
+}
+
+func issue(t *testing.T, p *Principal) *Tokens {
+	t.Helper()
+	tokens, err := GenerateTokens(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+func TestRefreshRotates(t *testing.T) {
+	p := &Principal{ID: "c1", Email: "c1@army", Role: "commander"}
+	store := users{p.Email: p}
+	tokens := issue(t, p)
+
+	fresh, err := Refresh(context.Background(), tokens.RefreshToken, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Refresh(context.Background(), tokens.RefreshToken, store); err == nil {
+		t.Fatal("refresh token was accepted twice")
+	}
+	if _, err := Refresh(context.Background(), fresh.RefreshToken, store); err != nil {
+		t.Fatalf("rotated refresh token: %v", err)
+	}
+	if _, err := Refresh(context.Background(), tokens.AccessToken, store); err == nil {
+		t.Fatal("access token was accepted as refresh token")
+	}
+}
+
+func TestRefreshReloadsUser(t *testing.T) {
+	p := &Principal{ID: "c1", Email: "c1@army", Role: "admin"}
+	tokens := issue(t, p)
+
+	demoted := &Principal{ID: "c1", Email: "c1@army", Role: "soldier"}
+	fresh, err := Refresh(context.Background(), tokens.RefreshToken, users{p.Email: demoted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ExtractClaim(fresh.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Role(claims); got != "soldier" {
+		t.Errorf("refreshed role = %q, want soldier", got)
+	}
+
+	tests := []struct {
+		name  string
+		users users
+	}{
+		{"deleted", users{}},
+		{"email reassigned", users{p.Email: {ID: "c2", Email: p.Email, Role: "admin"}}},
+	}
+	for _, tt := range tests {
+		tokens := issue(t, p)
+		if _, err := Refresh(context.Background(), tokens.RefreshToken, tt.users); !errors.Is(err, ErrUnknownUser) {
+			t.Errorf("%s user: err = %v, want ErrUnknownUser", tt.name, err)
+		}
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	tokens := issue(t, &Principal{ID: "c1", Email: "c1@army", Role: "commander"})
+
+	if err := Revoke(tokens.RefreshToken, "c2"); !errors.Is(err, ErrForeignToken) {
+		t.Fatalf("revoking another user's token: err = %v, want ErrForeignToken", err)
+	}
+	if _, err := ExtractClaim(tokens.AccessToken); err != nil {
+		t.Fatalf("access token before revocation: %v", err)
+	}
+	if err := Revoke(tokens.AccessToken, "c1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractClaim(tokens.AccessToken); err == nil {
+		t.Fatal("revoked access token was accepted")
+	}
+}
@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/Salikhov079/military/api/apierror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report validator errors under the JSON field names clients send.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				return f.Name
+			}
+			return name
+		})
+	}
+}
+
+// BindJSON decodes the request body into v and validates it. On failure it
+// writes an application/problem+json response and returns false.
+func BindJSON(ctx *gin.Context, v interface{}) bool {
+	if err := ctx.ShouldBindJSON(v); err != nil {
+		detail, params := describe(err)
+		apierror.AbortWithValidation(ctx, detail, params)
+		return false
+	}
+	if params := Validate(v); len(params) > 0 {
+		apierror.AbortWithValidation(ctx, "", params)
+		return false
+	}
+	return true
+}
+
+// describe turns a binding error into a client-facing message without
+// exposing decoder or validator internals.
+func describe(err error) (string, []apierror.InvalidParam) {
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+		fieldErrs validator.ValidationErrors
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return "request body is empty", nil
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body is not valid JSON", nil
+	case errors.As(err, &typeErr):
+		return "", []apierror.InvalidParam{{Name: typeErr.Field, Reason: "must be of type " + typeErr.Type.String()}}
+	case errors.As(err, &fieldErrs):
+		params := make([]apierror.InvalidParam, 0, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			params = append(params, apierror.InvalidParam{Name: fe.Field(), Reason: reason(fe)})
+		}
+		return "", params
+	default:
+		return "request could not be decoded", nil
+	}
+}
+
+func reason(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min", "gte":
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		return "must be at most " + fe.Param()
+	default:
+		return "is invalid"
+	}
+}
+
+// QueryDate returns the required query parameter name, checked to be a
+// YYYY-MM-DD date. On failure it writes an application/problem+json response
+// and returns false.
+func QueryDate(ctx *gin.Context, name string) (string, bool) {
+	c := &checker{}
+	v := ctx.Query(name)
+	c.date(name, v, true)
+	if len(c.params) > 0 {
+		apierror.AbortWithValidation(ctx, "", c.params)
+		return "", false
+	}
+	return v, true
+}
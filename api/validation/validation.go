@@ -0,0 +1,179 @@
+package validation
+
+import (
+	"net/mail"
+	"regexp"
+	"time"
+
+	"github.com/Salikhov079/military/api/apierror"
+	ai "github.com/Salikhov079/military/genprotos/ai"
+	mil "github.com/Salikhov079/military/genprotos/militaries"
+	pb "github.com/Salikhov079/military/genprotos/soldiers"
+)
+
+// DateLayout is the ISO 8601 calendar date format used by every date field.
+const DateLayout = "2006-01-02"
+
+var phoneRe = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+
+type checker struct {
+	params []apierror.InvalidParam
+}
+
+func (c *checker) fail(name, reason string) {
+	c.params = append(c.params, apierror.InvalidParam{Name: name, Reason: reason})
+}
+
+func (c *checker) required(name, v string) bool {
+	if v == "" {
+		c.fail(name, "is required")
+		return false
+	}
+	return true
+}
+
+func (c *checker) email(name, v string, required bool) {
+	if (!required && v == "") || (required && !c.required(name, v)) {
+		return
+	}
+	if addr, err := mail.ParseAddress(v); err != nil || addr.Address != v {
+		c.fail(name, "must be a valid email address")
+	}
+}
+
+func (c *checker) phone(name, v string, required bool) {
+	if (!required && v == "") || (required && !c.required(name, v)) {
+		return
+	}
+	if !phoneRe.MatchString(v) {
+		c.fail(name, "must be a phone number of 7 to 15 digits, optionally starting with +")
+	}
+}
+
+func (c *checker) date(name, v string, required bool) (time.Time, bool) {
+	if (!required && v == "") || (required && !c.required(name, v)) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(DateLayout, v)
+	if err != nil {
+		c.fail(name, "must be a date in the format YYYY-MM-DD")
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func (c *checker) pastDate(name, v string, required bool) {
+	if t, ok := c.date(name, v, required); ok && t.After(time.Now()) {
+		c.fail(name, "must not be in the future")
+	}
+}
+
+func (c *checker) period(startName, start, endName, end string) {
+	from, ok1 := c.date(startName, start, false)
+	to, ok2 := c.date(endName, end, false)
+	if ok1 && ok2 && to.Before(from) {
+		c.fail(endName, "must not be before "+startName)
+	}
+}
+
+func (c *checker) nonNegative(name string, v int32) {
+	if v < 0 {
+		c.fail(name, "must not be negative")
+	}
+}
+
+func (c *checker) positive(name string, v int32) {
+	if v <= 0 {
+		c.fail(name, "must be greater than zero")
+	}
+}
+
+// Validate checks a request bound by a handler and returns the fields that
+// are invalid. Types without rules are always valid.
+func Validate(v interface{}) []apierror.InvalidParam {
+	c := &checker{}
+	switch req := v.(type) {
+	case *ai.AiCHat:
+		c.required("text", req.Text)
+	case *pb.SoldierReq:
+		c.required("name", req.Name)
+		c.email("email", req.Email, true)
+		c.pastDate("date_of_birth", req.DateOfBirth, true)
+		c.phone("phone_number", req.PhoneNumber, true)
+		c.required("group_id", req.GroupId)
+		c.date("join_date", req.JoinDate, true)
+		c.period("join_date", req.JoinDate, "end_date", req.EndDate)
+	case *pb.Soldier:
+		c.required("name", req.Name)
+		c.email("email", req.Email, true)
+		c.pastDate("date_of_birth", req.DateOfBirth, true)
+		c.phone("phone_number", req.PhoneNumber, true)
+		c.period("join_date", req.JoinDate, "end_date", req.EndDate)
+	case *pb.CommanderReq:
+		c.required("name", req.Name)
+		c.email("email", req.Email, true)
+		c.pastDate("date_of_birth", req.DateOfBirth, true)
+		c.phone("phone_number", req.PhoneNumber, true)
+		c.required("position", req.Position)
+	case *pb.Commander:
+		c.required("name", req.Name)
+		c.email("email", req.Email, true)
+		c.pastDate("date_of_birth", req.DateOfBirth, false)
+		c.phone("phone_number", req.PhoneNumber, false)
+	case *pb.GroupReq:
+		c.required("name", req.Name)
+		c.required("department_id", req.DepartmentId)
+	case *pb.Group:
+		c.required("name", req.Name)
+	case *pb.Department:
+		c.required("name", req.Name)
+	case *pb.UseB:
+		c.required("soldier_id", req.SoldierId)
+		c.date("date", req.Date, true)
+		c.nonNegative("quantity_weapon", req.QuantityWeapon)
+		c.nonNegative("quantity_big_weapon", req.QuantityBigWeapon)
+		if req.QuantityWeapon == 0 && req.QuantityBigWeapon == 0 {
+			c.fail("quantity_weapon", "quantity_weapon or quantity_big_weapon must be greater than zero")
+		}
+	case *pb.UseF:
+		c.required("soldier_id", req.SoldierId)
+		c.date("date", req.Date, true)
+		c.nonNegative("diesel", req.Diesel)
+		c.nonNegative("petrol", req.Petrol)
+		if req.Diesel == 0 && req.Petrol == 0 {
+			c.fail("diesel", "diesel or petrol must be greater than zero")
+		}
+	case *mil.BulletReq:
+		c.required("type", req.Type)
+		if req.Caliber <= 0 {
+			c.fail("caliber", "must be greater than zero")
+		}
+		c.nonNegative("quantity", req.Quantity)
+	case *mil.Bullet:
+		c.required("type", req.Type)
+		c.nonNegative("quantity", req.Quantity)
+	case *mil.FuelReq:
+		c.required("type", req.Type)
+		c.nonNegative("quantity", req.Quantity)
+	case *mil.Fuel:
+		c.required("type", req.Type)
+		c.nonNegative("quantity", req.Quantity)
+	case *mil.TechniqueReq:
+		c.required("model", req.Model)
+		c.required("type", req.Type)
+		c.nonNegative("quantity", req.Quantity)
+	case *mil.Technique:
+		c.required("model", req.Model)
+		c.nonNegative("quantity", req.Quantity)
+	case *mil.BulletAddSub:
+		c.required("name", req.Name)
+		c.positive("quantity", req.Quantity)
+	case *mil.FuelAddSub:
+		c.required("name", req.Name)
+		c.positive("quantity", req.Quantity)
+	case *mil.TechniqueAddSub:
+		c.required("name", req.Name)
+		c.positive("quantity", req.Quantity)
+	}
+	return c.params
+}
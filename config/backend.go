@@ -0,0 +1,112 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// Backend describes how the gateway connects to one gRPC service.
+type Backend struct {
+	Target string `yaml:"target"`
+
+	KeepaliveTime    time.Duration `yaml:"keepalive_time"`
+	KeepaliveTimeout time.Duration `yaml:"keepalive_timeout"`
+	MaxRecvMsgSize   int           `yaml:"max_recv_msg_size"`
+	MaxSendMsgSize   int           `yaml:"max_send_msg_size"`
+
+	Timeout time.Duration `yaml:"timeout"`
+	Retry   Retry         `yaml:"retry"`
+	Breaker Breaker       `yaml:"breaker"`
+
+	TLS TLS `yaml:"tls"`
+}
+
+// Retry configures retries of idempotent RPCs. Methods is a comma-separated
+// list of method names, e.g. "Get,GetAll"; other methods are never retried.
+type Retry struct {
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	Methods        string        `yaml:"methods"`
+}
+
+// MethodList returns Methods split on commas.
+func (r Retry) MethodList() []string {
+	var methods []string
+	for _, m := range strings.Split(r.Methods, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// Breaker configures the circuit breaker. It opens after FailureThreshold
+// consecutive failures and tries the backend again after OpenTimeout. A zero
+// FailureThreshold disables it.
+type Breaker struct {
+	FailureThreshold int           `yaml:"failure_threshold"`
+	OpenTimeout      time.Duration `yaml:"open_timeout"`
+}
+
+// TLS configures transport security for a backend connection. When Enabled
+// is false the connection is plaintext.
+type TLS struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+func defaultBackend(target string) Backend {
+	return Backend{
+		Target:           target,
+		KeepaliveTime:    30 * time.Second,
+		KeepaliveTimeout: 10 * time.Second,
+		MaxRecvMsgSize:   4 << 20,
+		MaxSendMsgSize:   4 << 20,
+
+		Timeout: 5 * time.Second,
+		Retry: Retry{
+			MaxAttempts:    3,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     time.Second,
+			Methods:        "Get,GetAll,GetHistory,StatistikWeapons,FuelStatistik",
+		},
+		Breaker: Breaker{
+			FailureThreshold: 5,
+			OpenTimeout:      30 * time.Second,
+		},
+	}
+}
+
+// withEnv applies the environment overrides for the backend, e.g.
+// SOLDIERS_TARGET or SOLDIERS_TLS_CA_FILE for prefix "SOLDIERS".
+func (b Backend) withEnv(prefix string) Backend {
+	b.Target = cast.ToString(getOrReturnDefaultValue(prefix+"_TARGET", b.Target))
+
+	b.KeepaliveTime = cast.ToDuration(getOrReturnDefaultValue(prefix+"_KEEPALIVE_TIME", b.KeepaliveTime))
+	b.KeepaliveTimeout = cast.ToDuration(getOrReturnDefaultValue(prefix+"_KEEPALIVE_TIMEOUT", b.KeepaliveTimeout))
+	b.MaxRecvMsgSize = cast.ToInt(getOrReturnDefaultValue(prefix+"_MAX_RECV_MSG_SIZE", b.MaxRecvMsgSize))
+	b.MaxSendMsgSize = cast.ToInt(getOrReturnDefaultValue(prefix+"_MAX_SEND_MSG_SIZE", b.MaxSendMsgSize))
+
+	b.Timeout = cast.ToDuration(getOrReturnDefaultValue(prefix+"_TIMEOUT", b.Timeout))
+	b.Retry.MaxAttempts = cast.ToInt(getOrReturnDefaultValue(prefix+"_RETRY_MAX_ATTEMPTS", b.Retry.MaxAttempts))
+	b.Retry.InitialBackoff = cast.ToDuration(getOrReturnDefaultValue(prefix+"_RETRY_INITIAL_BACKOFF", b.Retry.InitialBackoff))
+	b.Retry.MaxBackoff = cast.ToDuration(getOrReturnDefaultValue(prefix+"_RETRY_MAX_BACKOFF", b.Retry.MaxBackoff))
+	b.Retry.Methods = cast.ToString(getOrReturnDefaultValue(prefix+"_RETRY_METHODS", b.Retry.Methods))
+	b.Breaker.FailureThreshold = cast.ToInt(getOrReturnDefaultValue(prefix+"_BREAKER_FAILURE_THRESHOLD", b.Breaker.FailureThreshold))
+	b.Breaker.OpenTimeout = cast.ToDuration(getOrReturnDefaultValue(prefix+"_BREAKER_OPEN_TIMEOUT", b.Breaker.OpenTimeout))
+
+	b.TLS.Enabled = cast.ToBool(getOrReturnDefaultValue(prefix+"_TLS_ENABLED", b.TLS.Enabled))
+	b.TLS.CAFile = cast.ToString(getOrReturnDefaultValue(prefix+"_TLS_CA_FILE", b.TLS.CAFile))
+	b.TLS.CertFile = cast.ToString(getOrReturnDefaultValue(prefix+"_TLS_CERT_FILE", b.TLS.CertFile))
+	b.TLS.KeyFile = cast.ToString(getOrReturnDefaultValue(prefix+"_TLS_KEY_FILE", b.TLS.KeyFile))
+	b.TLS.ServerName = cast.ToString(getOrReturnDefaultValue(prefix+"_TLS_SERVER_NAME", b.TLS.ServerName))
+	b.TLS.InsecureSkipVerify = cast.ToBool(getOrReturnDefaultValue(prefix+"_TLS_INSECURE_SKIP_VERIFY", b.TLS.InsecureSkipVerify))
+	return b
+}
@@ -1,49 +1,365 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
 )
 
+// DefaultTokenKey is the HMAC secret of a fresh checkout. It is public, so
+// the gateway refuses to start with it while HS256 tokens are accepted.
+const DefaultTokenKey = "my_secret_key"
+
 type Config struct {
-	HTTPPort string
+	HTTPPort string `yaml:"http_port"`
+
+	LogLevel      string  `yaml:"log_level"`
+	LogSampleRate float64 `yaml:"log_sample_rate"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+
+	PostgresHost     string `yaml:"postgres_host"`
+	PostgresPort     int    `yaml:"postgres_port"`
+	PostgresUser     string `yaml:"postgres_user"`
+	PostgresPassword string `yaml:"postgres_password"`
+	PostgresDatabase string `yaml:"postgres_database"`
+
+	DefaultOffset string `yaml:"default_offset"`
+	DefaultLimit  string `yaml:"default_limit"`
+
+	Militaries Backend `yaml:"militaries"`
+	Soldiers   Backend `yaml:"soldiers"`
+	AI         Backend `yaml:"ai"`
+
+	TokenKey        string        `yaml:"token_key"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	CredentialsPath string        `yaml:"credentials_path"`
+
+	JWTAlgorithms  string        `yaml:"jwt_algorithms"`
+	JWKSSource     string        `yaml:"jwks_source"`
+	JWKSRefresh    time.Duration `yaml:"jwks_refresh"`
+	SigningKeyPath string        `yaml:"signing_key_path"`
+	SigningKeyID   string        `yaml:"signing_key_id"`
+
+	LegacyAuthHeader bool `yaml:"legacy_auth_header"`
+
+	AuditLogPath string `yaml:"audit_log_path"`
 
-	PostgresHost     string
-	PostgresPort     int
-	PostgresUser     string
-	PostgresPassword string
-	PostgresDatabase string
+	SagaJournalPath string `yaml:"saga_journal_path"`
 
-	DefaultOffset string
-	DefaultLimit  string
+	IdempotencyStore      string        `yaml:"idempotency_store"`
+	IdempotencyFile       string        `yaml:"idempotency_file"`
+	IdempotencyTTL        time.Duration `yaml:"idempotency_ttl"`
+	IdempotencyMaxEntries int           `yaml:"idempotency_max_entries"`
 
-	TokenKey string
+	CacheStore  string                   `yaml:"cache_store"`
+	CacheSize   int                      `yaml:"cache_size"`
+	CacheRoutes map[string]time.Duration `yaml:"cache_routes"`
+
+	RateLimitStore string            `yaml:"rate_limit_store"`
+	RateLimits     map[string]string `yaml:"rate_limits"`
+	TrustedProxies string            `yaml:"trusted_proxies"`
+
+	RedisAddr     string        `yaml:"redis_addr"`
+	RedisPassword string        `yaml:"redis_password"`
+	RedisDB       int           `yaml:"redis_db"`
+	RedisTimeout  time.Duration `yaml:"redis_timeout"`
+
+	PolicyPath string `yaml:"policy_path"`
+
+	HealthTimeout time.Duration `yaml:"health_timeout"`
+
+	MetricsPort           string `yaml:"metrics_port"`
+	MetricsTechniqueTypes string `yaml:"metrics_technique_types"`
+
+	AIStreamHeartbeat    time.Duration `yaml:"ai_stream_heartbeat"`
+	AIStreamResumeWindow time.Duration `yaml:"ai_stream_resume_window"`
+	AIStreamOrigins      string        `yaml:"ai_stream_origins"`
+
+	AIThreadStore       string `yaml:"ai_thread_store"`
+	AIThreadFile        string `yaml:"ai_thread_file"`
+	AIThreadMaxThreads  int    `yaml:"ai_thread_max_threads"`
+	AIThreadMaxMessages int    `yaml:"ai_thread_max_messages"`
+
+	ServiceName        string  `yaml:"service_name"`
+	TracingExporter    string  `yaml:"tracing_exporter"`
+	TracingEndpoint    string  `yaml:"tracing_endpoint"`
+	TracingInsecure    bool    `yaml:"tracing_insecure"`
+	TracingFile        string  `yaml:"tracing_file"`
+	TracingSampleRatio float64 `yaml:"tracing_sample_ratio"`
 }
 
+// Load builds the configuration from, in increasing priority, the built-in
+// defaults, the YAML file named by CONFIG_FILE (config/config.yaml by
+// default, skipped when missing) and environment variables.
 func Load() Config {
 	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found")
+		slog.Info("No .env file found")
 	}
 
-	config := Config{}
+	config := Config{
+		HTTPPort: ":8080",
+
+		LogLevel:      "info",
+		LogSampleRate: 1,
 
-	config.HTTPPort = cast.ToString(getOrReturnDefaultValue("HTTP_PORT", ":8080"))
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      75 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownTimeout:   30 * time.Second,
 
-	config.PostgresHost = cast.ToString(getOrReturnDefaultValue("POSTGRES_HOST", "localhost"))
-	config.PostgresPort = cast.ToInt(getOrReturnDefaultValue("POSTGRES_PORT", 5432))
-	config.PostgresUser = cast.ToString(getOrReturnDefaultValue("POSTGRES_USER", "postgres"))
-	config.PostgresPassword = cast.ToString(getOrReturnDefaultValue("POSTGRES_PASSWORD", "1234"))
-	config.PostgresDatabase = cast.ToString(getOrReturnDefaultValue("POSTGRES_DATABASE", "rent_car"))
+		PostgresHost:     "localhost",
+		PostgresPort:     5432,
+		PostgresUser:     "postgres",
+		PostgresPassword: "1234",
+		PostgresDatabase: "rent_car",
+
+		DefaultOffset: "0",
+		DefaultLimit:  "10",
+
+		Militaries: defaultBackend("localhost:8085"),
+		Soldiers:   defaultBackend("localhost:7070"),
+		AI:         defaultBackend("localhost:8086"),
+
+		TokenKey:        DefaultTokenKey,
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 7 * 24 * time.Hour,
+		CredentialsPath: "config/credentials.yaml",
+
+		JWKSRefresh:   5 * time.Minute,
+
+		LegacyAuthHeader: true,
+
+		AuditLogPath: "audit.jsonl",
+
+		SagaJournalPath: "saga.journal.jsonl",
+
+		IdempotencyStore:      "memory",
+		IdempotencyFile:       "idempotency.jsonl",
+		IdempotencyTTL:        24 * time.Hour,
+		IdempotencyMaxEntries: 100000,
+
+		CacheStore: "memory",
+		CacheSize:  1000,
+		CacheRoutes: map[string]time.Duration{
+			"/bullet/getall":    30 * time.Second,
+			"/fuel/getall":      30 * time.Second,
+			"/technique/getall": 30 * time.Second,
+			"/soldier/dashbord": 15 * time.Second,
+		},
+
+		RateLimitStore: "memory",
+		RateLimits: map[string]string{
+			"default":            "600/1m",
+			"/auth":              "20/1m",
+			"/ai":                "20/1m",
+			"/soldier/usebullet": "60/1m",
+			"/soldier/usefuel":   "60/1m",
+		},
+
+		RedisAddr:    "localhost:6379",
+		RedisTimeout: 500 * time.Millisecond,
+
+		PolicyPath: "config/policy.yaml",
+
+		HealthTimeout: 2 * time.Second,
+
+		AIStreamHeartbeat:    15 * time.Second,
+		AIStreamResumeWindow: 30 * time.Second,
+
+		AIThreadStore:       "file",
+		AIThreadFile:        "ai_threads.jsonl",
+		AIThreadMaxThreads:  100,
+		AIThreadMaxMessages: 1000,
+
+		ServiceName:        "military-api-gateway",
+		TracingExporter:    "none",
+		TracingEndpoint:    "localhost:4317",
+		TracingInsecure:    true,
+		TracingSampleRatio: 1,
+	}
+	// Chat completions take much longer than the CRUD services.
+	config.AI.Timeout = time.Minute
+
+	path := cast.ToString(getOrReturnDefaultValue("CONFIG_FILE", "config/config.yaml"))
+	if err := loadFile(path, &config); err != nil {
+		slog.Error("Error while loading config file", "path", path, "error", err)
+	}
 
-	config.DefaultOffset = cast.ToString(getOrReturnDefaultValue("DEFAULT_OFFSET", "0"))
-	config.DefaultLimit = cast.ToString(getOrReturnDefaultValue("DEFAULT_LIMIT", "10"))
-	config.TokenKey = cast.ToString(getOrReturnDefaultValue("TokenKey", "my_secret_key"))
+	config.HTTPPort = cast.ToString(getOrReturnDefaultValue("HTTP_PORT", config.HTTPPort))
+
+	config.LogLevel = cast.ToString(getOrReturnDefaultValue("LOG_LEVEL", config.LogLevel))
+	config.LogSampleRate = cast.ToFloat64(getOrReturnDefaultValue("LOG_SAMPLE_RATE", config.LogSampleRate))
+
+	config.ReadTimeout = cast.ToDuration(getOrReturnDefaultValue("READ_TIMEOUT", config.ReadTimeout))
+	config.ReadHeaderTimeout = cast.ToDuration(getOrReturnDefaultValue("READ_HEADER_TIMEOUT", config.ReadHeaderTimeout))
+	config.WriteTimeout = cast.ToDuration(getOrReturnDefaultValue("WRITE_TIMEOUT", config.WriteTimeout))
+	config.IdleTimeout = cast.ToDuration(getOrReturnDefaultValue("IDLE_TIMEOUT", config.IdleTimeout))
+	config.ShutdownTimeout = cast.ToDuration(getOrReturnDefaultValue("SHUTDOWN_TIMEOUT", config.ShutdownTimeout))
+
+	config.PostgresHost = cast.ToString(getOrReturnDefaultValue("POSTGRES_HOST", config.PostgresHost))
+	config.PostgresPort = cast.ToInt(getOrReturnDefaultValue("POSTGRES_PORT", config.PostgresPort))
+	config.PostgresUser = cast.ToString(getOrReturnDefaultValue("POSTGRES_USER", config.PostgresUser))
+	config.PostgresPassword = cast.ToString(getOrReturnDefaultValue("POSTGRES_PASSWORD", config.PostgresPassword))
+	config.PostgresDatabase = cast.ToString(getOrReturnDefaultValue("POSTGRES_DATABASE", config.PostgresDatabase))
+
+	config.DefaultOffset = cast.ToString(getOrReturnDefaultValue("DEFAULT_OFFSET", config.DefaultOffset))
+	config.DefaultLimit = cast.ToString(getOrReturnDefaultValue("DEFAULT_LIMIT", config.DefaultLimit))
+
+	config.Militaries = config.Militaries.withEnv("MILITARIES")
+	config.Soldiers = config.Soldiers.withEnv("SOLDIERS")
+	config.AI = config.AI.withEnv("AI")
+
+	config.TokenKey = cast.ToString(getOrReturnDefaultValue("TokenKey", config.TokenKey))
+	config.AccessTokenTTL = cast.ToDuration(getOrReturnDefaultValue("ACCESS_TOKEN_TTL", config.AccessTokenTTL))
+	config.RefreshTokenTTL = cast.ToDuration(getOrReturnDefaultValue("REFRESH_TOKEN_TTL", config.RefreshTokenTTL))
+	config.CredentialsPath = cast.ToString(getOrReturnDefaultValue("CREDENTIALS_PATH", config.CredentialsPath))
+
+	config.JWTAlgorithms = cast.ToString(getOrReturnDefaultValue("JWT_ALGORITHMS", config.JWTAlgorithms))
+	config.JWKSSource = cast.ToString(getOrReturnDefaultValue("JWKS_SOURCE", config.JWKSSource))
+	config.JWKSRefresh = cast.ToDuration(getOrReturnDefaultValue("JWKS_REFRESH", config.JWKSRefresh))
+	config.SigningKeyPath = cast.ToString(getOrReturnDefaultValue("SIGNING_KEY_PATH", config.SigningKeyPath))
+	config.SigningKeyID = cast.ToString(getOrReturnDefaultValue("SIGNING_KEY_ID", config.SigningKeyID))
+	// Without an explicit allow-list, HMAC tokens are accepted only until a
+	// JWKS is configured.
+	if config.JWTAlgorithms == "" {
+		config.JWTAlgorithms = "HS256"
+		if config.JWKSSource != "" {
+			config.JWTAlgorithms = "RS256,ES256"
+		}
+	}
+
+	config.LegacyAuthHeader = cast.ToBool(getOrReturnDefaultValue("LEGACY_AUTH_HEADER", config.LegacyAuthHeader))
+
+	config.AuditLogPath = cast.ToString(getOrReturnDefaultValue("AUDIT_LOG_PATH", config.AuditLogPath))
+
+	config.SagaJournalPath = cast.ToString(getOrReturnDefaultValue("SAGA_JOURNAL_PATH", config.SagaJournalPath))
+
+	config.IdempotencyStore = cast.ToString(getOrReturnDefaultValue("IDEMPOTENCY_STORE", config.IdempotencyStore))
+	config.IdempotencyFile = cast.ToString(getOrReturnDefaultValue("IDEMPOTENCY_FILE", config.IdempotencyFile))
+	config.IdempotencyTTL = cast.ToDuration(getOrReturnDefaultValue("IDEMPOTENCY_TTL", config.IdempotencyTTL))
+	config.IdempotencyMaxEntries = cast.ToInt(getOrReturnDefaultValue("IDEMPOTENCY_MAX_ENTRIES", config.IdempotencyMaxEntries))
+
+	config.CacheStore = cast.ToString(getOrReturnDefaultValue("CACHE_STORE", config.CacheStore))
+	config.CacheSize = cast.ToInt(getOrReturnDefaultValue("CACHE_SIZE", config.CacheSize))
+	if routes, ok := os.LookupEnv("CACHE_ROUTES"); ok {
+		config.CacheRoutes = parseDurations(routes)
+	}
+
+	config.RateLimitStore = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_STORE", config.RateLimitStore))
+	if limits, ok := os.LookupEnv("RATE_LIMITS"); ok {
+		config.RateLimits = parsePairs(limits)
+	}
+	config.TrustedProxies = cast.ToString(getOrReturnDefaultValue("TRUSTED_PROXIES", config.TrustedProxies))
+
+	config.RedisAddr = cast.ToString(getOrReturnDefaultValue("REDIS_ADDR", config.RedisAddr))
+	config.RedisPassword = cast.ToString(getOrReturnDefaultValue("REDIS_PASSWORD", config.RedisPassword))
+	config.RedisDB = cast.ToInt(getOrReturnDefaultValue("REDIS_DB", config.RedisDB))
+	config.RedisTimeout = cast.ToDuration(getOrReturnDefaultValue("REDIS_TIMEOUT", config.RedisTimeout))
+
+	config.PolicyPath = cast.ToString(getOrReturnDefaultValue("POLICY_PATH", config.PolicyPath))
+
+	config.HealthTimeout = cast.ToDuration(getOrReturnDefaultValue("HEALTH_TIMEOUT", config.HealthTimeout))
+
+	config.MetricsPort = cast.ToString(getOrReturnDefaultValue("METRICS_PORT", config.MetricsPort))
+	config.MetricsTechniqueTypes = cast.ToString(getOrReturnDefaultValue("METRICS_TECHNIQUE_TYPES", config.MetricsTechniqueTypes))
+
+	config.AIStreamHeartbeat = cast.ToDuration(getOrReturnDefaultValue("AI_STREAM_HEARTBEAT", config.AIStreamHeartbeat))
+	config.AIStreamResumeWindow = cast.ToDuration(getOrReturnDefaultValue("AI_STREAM_RESUME_WINDOW", config.AIStreamResumeWindow))
+	config.AIStreamOrigins = cast.ToString(getOrReturnDefaultValue("AI_STREAM_ORIGINS", config.AIStreamOrigins))
+
+	config.AIThreadStore = cast.ToString(getOrReturnDefaultValue("AI_THREAD_STORE", config.AIThreadStore))
+	config.AIThreadFile = cast.ToString(getOrReturnDefaultValue("AI_THREAD_FILE", config.AIThreadFile))
+	config.AIThreadMaxThreads = cast.ToInt(getOrReturnDefaultValue("AI_THREAD_MAX_THREADS", config.AIThreadMaxThreads))
+	config.AIThreadMaxMessages = cast.ToInt(getOrReturnDefaultValue("AI_THREAD_MAX_MESSAGES", config.AIThreadMaxMessages))
+
+	config.ServiceName = cast.ToString(getOrReturnDefaultValue("SERVICE_NAME", config.ServiceName))
+	config.TracingExporter = cast.ToString(getOrReturnDefaultValue("TRACING_EXPORTER", config.TracingExporter))
+	config.TracingEndpoint = cast.ToString(getOrReturnDefaultValue("TRACING_ENDPOINT", config.TracingEndpoint))
+	config.TracingInsecure = cast.ToBool(getOrReturnDefaultValue("TRACING_INSECURE", config.TracingInsecure))
+	config.TracingFile = cast.ToString(getOrReturnDefaultValue("TRACING_FILE", config.TracingFile))
+	config.TracingSampleRatio = cast.ToFloat64(getOrReturnDefaultValue("TRACING_SAMPLE_RATIO", config.TracingSampleRatio))
 	return config
 }
 
+// Check reports a store setting naming a backend the gateway does not have,
+// such as a misspelled one, so it is not silently replaced by another.
+func (c Config) Check() error {
+	for _, s := range []struct {
+		name, value string
+		allowed     []string
+	}{
+		{"idempotency_store", c.IdempotencyStore, []string{"memory", "file"}},
+		{"cache_store", c.CacheStore, []string{"memory", "redis", "none"}},
+		{"rate_limit_store", c.RateLimitStore, []string{"memory", "redis", "none"}},
+		{"ai_thread_store", c.AIThreadStore, []string{"memory", "file"}},
+	} {
+		if !slices.Contains(s.allowed, s.value) {
+			return fmt.Errorf("unknown %s %q, want one of %s", s.name, s.value, strings.Join(s.allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// loadFile decodes the YAML file at path over config. Keys missing from the
+// file keep their current values; a missing file is not an error.
+func loadFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// parseDurations parses "key=duration" pairs separated by commas, such as
+// "/bullet/getall=30s,/soldier/dashbord=15s". Malformed pairs are skipped.
+func parseDurations(s string) map[string]time.Duration {
+	m := map[string]time.Duration{}
+	for key, value := range parsePairs(s) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			slog.Error("Error while parsing duration", "key", key, "error", err)
+			continue
+		}
+		m[key] = d
+	}
+	return m
+}
+
+// parsePairs parses "key=value" pairs separated by commas. Pairs without
+// "=" are skipped.
+func parsePairs(s string) map[string]string {
+	m := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return m
+}
+
 func getOrReturnDefaultValue(key string, defaultValue interface{}) interface{} {
 	val, exists := os.LookupEnv(key)
 
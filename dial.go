@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/Salikhov079/military/api/interceptor"
+	"github.com/Salikhov079/military/api/metrics"
+	"github.com/Salikhov079/military/api/requestid"
+	"github.com/Salikhov079/military/config"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// dial creates a client connection to the backend described by b. Every
+// call is traced and carries the request ID. Unary calls also get the
+// backend's deadline, are retried when idempotent and are guarded by its
+// circuit breaker; streams live as long as their context.
+func dial(b config.Backend) (*grpc.ClientConn, error) {
+	creds, err := transportCredentials(b.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Target, err)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(b.MaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(b.MaxSendMsgSize),
+		),
+		grpc.WithChainUnaryInterceptor(
+			metrics.UnaryClientInterceptor(),
+			requestid.UnaryClientInterceptor(),
+			interceptor.Timeout(b.Timeout),
+			interceptor.Retry(interceptor.RetryPolicy{
+				MaxAttempts:    b.Retry.MaxAttempts,
+				InitialBackoff: b.Retry.InitialBackoff,
+				MaxBackoff:     b.Retry.MaxBackoff,
+				Methods:        b.Retry.MethodList(),
+			}),
+			interceptor.NewBreaker(b.Breaker.FailureThreshold, b.Breaker.OpenTimeout).Unary(),
+		),
+		grpc.WithChainStreamInterceptor(
+			requestid.StreamClientInterceptor(),
+		),
+	}
+	if b.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    b.KeepaliveTime,
+			Timeout: b.KeepaliveTimeout,
+		}))
+	}
+	return grpc.NewClient(b.Target, opts...)
+}
+
+func transportCredentials(c config.TLS) (credentials.TransportCredentials, error) {
+	if !c.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	conf := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+		}
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(conf), nil
+}
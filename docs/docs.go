@@ -22,7 +22,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "CHat with AI",
+                "description": "CHat with AI as the caller; user_id, system_context, thread_id and the tool fields in the body are ignored. The message and reply are kept in the thread named by thread_id.",
                 "consumes": [
                     "application/json"
                 ],
@@ -42,6 +42,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/ai.AiCHat"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID, default when empty",
+                        "name": "thread_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -54,199 +60,238 @@ const docTemplate = `{
                     "401": {
                         "description": "Error while creating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Thread not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/ai/gethistory/{id}": {
+        "/ai/chat/stream": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "CHat with AI",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stream the AI reply as Server-Sent Events: \"chunk\" events carry partial text and \"tool\" events the tool calls the AI makes, then one \"done\" event carries the whole reply or one \"error\" event a problem. \"heartbeat\" events are sent while the AI is silent. Reconnect with the Last-Event-ID header, or the last_event_id parameter, to resume a stream within the resume window.",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
                     "AI"
                 ],
-                "summary": "GetHistory",
+                "summary": "Stream CHAT",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Message, required unless resuming",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID, default when empty",
+                        "name": "thread_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access token, for clients that cannot set the Authorization header",
+                        "name": "access_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Event stream",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Error while creating",
+                    "204": {
+                        "description": "Resumed stream has no more events",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Stream expired or thread not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
                     }
                 }
             }
         },
-        "/bullet/add": {
-            "put": {
+        "/ai/chat/stream/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Add quantity to a Bullet",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stop the AI reply of a stream, e.g. when an EventSource client navigates away",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bullet"
+                    "AI"
                 ],
-                "summary": "Add Quantity",
+                "summary": "Cancel Stream CHAT",
                 "parameters": [
                     {
-                        "description": "Bullet data",
-                        "name": "Bullet",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/militaries.BulletAddSub"
-                        }
+                        "type": "string",
+                        "description": "Stream ID, the part of an event ID before the colon",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Add Successful",
+                        "description": "Stream canceled",
                         "schema": {
-                            "$ref": "#/definitions/militaries.Void"
+                            "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Error while adding quantity",
+                    "404": {
+                        "description": "Stream expired",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/bullet/create": {
-            "post": {
+        "/ai/chat/ws": {
+            "get": {
                 "security": [
-                    {
-                        "BearerAuth": []
-                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new bullet",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Upgrade to a WebSocket and stream the AI reply as JSON StreamFrame messages with the same events as /ai/chat/stream. Send {\"type\":\"cancel\"} to stop the reply; closing the socket stops it after the resume window. Browsers may connect only from the gateway's origin and the configured ai_stream_origins.",
                 "tags": [
-                    "Bullet"
+                    "AI"
                 ],
-                "summary": "Create Bullet",
+                "summary": "Stream CHAT over WebSocket",
                 "parameters": [
                     {
-                        "description": "Bullet Request",
-                        "name": "BulletReq",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/militaries.BulletReq"
-                        }
+                        "type": "string",
+                        "description": "Message, required unless resuming",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Thread ID, default when empty",
+                        "name": "thread_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access token, for clients that cannot set the Authorization header",
+                        "name": "access_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "last_event_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Create Successful",
+                    "101": {
+                        "description": "Switching Protocols",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handler.StreamFrame"
                         }
                     },
-                    "401": {
-                        "description": "Error while creating",
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Origin not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Stream expired or thread not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/bullet/delete/{id}": {
-            "delete": {
+        "/ai/context": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing bullet",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the live inventory and personnel data sent to the AI with every chat message of the caller",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bullet"
-                ],
-                "summary": "Delete Bullet",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Bullet ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "AI"
                 ],
+                "summary": "AI Context",
                 "responses": {
                     "200": {
-                        "description": "Delete Successful",
+                        "description": "Get Successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handler.GroundingContext"
                         }
                     },
                     "401": {
-                        "description": "Error while deleting",
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/bullet/getall": {
+        "/ai/gethistory/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all bullets",
+                "description": "Get the complete chat history the AI service keeps for a user, optionally limited to a time range; messages without a time are left out of a range. id must be the caller's user ID unless the policy allows the caller's role on /users/:user_id/ai. Use /ai/history for the messages of one thread.",
                 "consumes": [
                     "application/json"
                 ],
@@ -254,141 +299,250 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bullet"
+                    "AI"
                 ],
-                "summary": "Get All Bullets",
+                "summary": "GetHistory",
                 "parameters": [
                     {
-                        "type": "number",
-                        "name": "caliber",
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC 3339)",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "name": "quantity",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "name": "type",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. request_text",
+                        "name": "sort",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Get All Successful",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HistoryPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
                         "schema": {
-                            "$ref": "#/definitions/militaries.AllBullets"
+                            "$ref": "#/definitions/apierror.Problem"
                         }
                     },
                     "401": {
-                        "description": "Error while getting all",
+                        "description": "Error while creating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to read the history of another user",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/bullet/getbyid/{id}": {
+        "/ai/history": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get an existing bullet by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the messages of an AI conversation thread of the caller, oldest first, optionally limited to a time range. Roles the policy allows on /users/:user_id/ai may read the history of another user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bullet"
+                    "AI"
                 ],
-                "summary": "Get Bullet",
+                "summary": "Thread History",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bullet ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Thread ID, default when empty",
+                        "name": "thread_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID, roles the policy allows on /users/:user_id/ai only",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. request_text",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "Get Successful",
                         "schema": {
-                            "$ref": "#/definitions/militaries.Bullet"
+                            "$ref": "#/definitions/handler.ThreadHistoryPage"
                         }
                     },
-                    "401": {
-                        "description": "Error while getting",
+                    "400": {
+                        "description": "Invalid query parameter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to read the history of another user",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Thread not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/bullet/sub": {
-            "put": {
+        "/ai/threads": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Subtract quantity from a Bullet",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the AI conversation threads of the caller, the most recently updated first. Roles the policy allows on /users/:user_id/ai may list the threads of another user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bullet"
+                    "AI"
                 ],
-                "summary": "Subtract Quantity",
+                "summary": "List Threads",
                 "parameters": [
                     {
-                        "description": "Bullet data",
-                        "name": "Bullet",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/militaries.BulletAddSub"
-                        }
+                        "type": "string",
+                        "description": "User ID, roles the policy allows on /users/:user_id/ai only",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. title",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Subtract Successful",
+                        "description": "Get All Successful",
                         "schema": {
-                            "$ref": "#/definitions/militaries.Void"
+                            "$ref": "#/definitions/handler.ThreadPage"
                         }
                     },
-                    "500": {
-                        "description": "Error while subtracting quantity",
+                    "400": {
+                        "description": "Invalid pagination parameter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to read threads of another user",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/bullet/update/{id}": {
-            "put": {
+            },
+            "post": {
                 "security": [
-                    {
-                        "BearerAuth": []
-                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing bullet",
+                "description": "Start a new AI conversation thread of the caller. Send its ID as thread_id with chat messages. A user may have up to ai_thread_max_threads threads besides the default one.",
                 "consumes": [
                     "application/json"
                 ],
@@ -396,51 +550,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bullet"
+                    "AI"
                 ],
-                "summary": "Update Bullet",
+                "summary": "Create Thread",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bullet ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Bullet",
-                        "name": "Bullet",
+                        "description": "Thread",
+                        "name": "Thread",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/militaries.Bullet"
+                            "$ref": "#/definitions/handler.ThreadReq"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Update Successful",
+                    "201": {
+                        "description": "Create Successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/thread.Thread"
                         }
                     },
-                    "401": {
-                        "description": "Error while updating",
+                    "400": {
+                        "description": "Invalid request or thread limit reached",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Problem"
                         }
                     }
                 }
             }
         },
-        "/commander/create": {
-            "post": {
+        "/ai/threads/{id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new commander",
+                "description": "Change the title of an AI conversation thread of the caller. Roles the policy allows on /users/:user_id/ai may rename a thread of another user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -448,61 +595,87 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Commander"
+                    "AI"
                 ],
-                "summary": "Create Commander",
+                "summary": "Rename Thread",
                 "parameters": [
                     {
-                        "description": "Commander Request",
-                        "name": "CommanderReq",
+                        "type": "string",
+                        "description": "Thread ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID, roles the policy allows on /users/:user_id/ai only",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Thread",
+                        "name": "Thread",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/soldiers.CreateCommand"
+                            "$ref": "#/definitions/handler.ThreadReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Create Successful",
+                        "description": "Update Successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/thread.Thread"
                         }
                     },
-                    "401": {
-                        "description": "Error while creating",
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change threads of another user",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Thread not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/commander/delete/{id}": {
+            },
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing commander",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete an AI conversation thread of the caller and its messages. The default thread is created again by the next message without a thread. Roles the policy allows on /users/:user_id/ai may delete a thread of another user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Commander"
+                    "AI"
                 ],
-                "summary": "Delete Commander",
+                "summary": "Delete Thread",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Commander ID",
+                        "description": "Thread ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID, roles the policy allows on /users/:user_id/ai only",
+                        "name": "user_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -512,66 +685,60 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Error while deleting",
+                    "403": {
+                        "description": "Not allowed to change threads of another user",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Thread not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/commander/get/{id}": {
+        "/ai/tools": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get an existing commander by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the read-only queries the AI may run on behalf of the caller, with the JSON schema of their arguments",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Commander"
-                ],
-                "summary": "Get Commander",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Commander ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "AI"
                 ],
+                "summary": "AI Tools",
                 "responses": {
                     "200": {
                         "description": "Get Successful",
                         "schema": {
-                            "$ref": "#/definitions/soldiers.Commander"
+                            "$ref": "#/definitions/handler.ToolList"
                         }
                     },
                     "401": {
-                        "description": "Error while getting",
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/commander/getall": {
+        "/audit": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all commanders",
+                "description": "Get audit records of mutating requests, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -579,18 +746,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Commander"
+                    "Audit"
                 ],
-                "summary": "Get All Commanders",
+                "summary": "Query Audit Log",
                 "parameters": [
                     {
                         "type": "string",
-                        "name": "email",
+                        "description": "Actor (JWT subject)",
+                        "name": "actor",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "name": "name",
+                        "description": "Entity, e.g. soldier or bullet",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return only the newest N records",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -598,26 +785,30 @@ const docTemplate = `{
                     "200": {
                         "description": "Get All Successful",
                         "schema": {
-                            "$ref": "#/definitions/soldiers.AllCommanders"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/audit.Record"
+                            }
                         }
                     },
-                    "401": {
-                        "description": "Error while getting all",
+                    "400": {
+                        "description": "Invalid query parameter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Error while reading audit log",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/commander/update/{id}": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update an existing commander",
+        "/auth/login": {
+            "post": {
+                "description": "Exchange email and password for an access and a refresh token",
                 "consumes": [
                     "application/json"
                 ],
@@ -625,51 +816,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Commander"
+                    "Auth"
                 ],
-                "summary": "Update Commander",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Commander ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Commander",
-                        "name": "Commander",
+                        "description": "Credentials",
+                        "name": "Login",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/soldiers.Commander"
+                            "$ref": "#/definitions/handler.LoginReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Update Successful",
+                        "description": "Login Successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/token.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
                         }
                     },
                     "401": {
-                        "description": "Error while updating",
+                        "description": "Invalid email or password",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/department/create": {
+        "/auth/logout": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new department",
+                "description": "Revoke the access token of the request and, when given, the caller's refresh token",
                 "consumes": [
                     "application/json"
                 ],
@@ -677,44 +867,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Department"
+                    "Auth"
                 ],
-                "summary": "Create Department",
+                "summary": "Logout",
                 "parameters": [
                     {
-                        "description": "Department",
-                        "name": "Department",
+                        "description": "Refresh token",
+                        "name": "Logout",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/soldiers.CreateDeportment"
+                            "$ref": "#/definitions/handler.LogoutReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Create Successful",
+                        "description": "Logout Successful",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Error while creating",
+                    "400": {
+                        "description": "Invalid refresh token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Refresh token of another user",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/department/delete/{id}": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Delete an existing department",
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new token pair carrying the user's current role. The presented refresh token is revoked.",
                 "consumes": [
                     "application/json"
                 ],
@@ -722,42 +912,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Department"
+                    "Auth"
                 ],
-                "summary": "Delete Department",
+                "summary": "Refresh",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Department ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Refresh token",
+                        "name": "Refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Delete Successful",
+                        "description": "Refresh Successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/token.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
                         }
                     },
                     "401": {
-                        "description": "Error while deleting",
+                        "description": "Invalid refresh token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/department/get/{id}": {
-            "get": {
+        "/bullet/add": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get an existing department by ID",
+                "description": "Add quantity to a Bullet",
                 "consumes": [
                     "application/json"
                 ],
@@ -765,153 +963,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Department"
+                    "Bullet"
                 ],
-                "summary": "Get Department",
+                "summary": "Add Quantity",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Department ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Get Successful",
-                        "schema": {
-                            "$ref": "#/definitions/soldiers.Department"
-                        }
-                    },
-                    "401": {
-                        "description": "Error while getting",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/department/getall": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all departments",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Department"
-                ],
-                "summary": "Get All Departments",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "name": "name",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Get All Successful",
-                        "schema": {
-                            "$ref": "#/definitions/soldiers.AllDepartments"
-                        }
-                    },
-                    "401": {
-                        "description": "Error while getting all",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/department/update/{id}": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update an existing department",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Department"
-                ],
-                "summary": "Update Department",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Department ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Department",
-                        "name": "Department",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/soldiers.Department"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Update Successful",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "401": {
-                        "description": "Error while updating",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/fuel/add": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Add quantity to a Fuel",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Fuel"
-                ],
-                "summary": "Add Quantity",
-                "parameters": [
-                    {
-                        "description": "Fuel data",
-                        "name": "Fuel",
+                        "description": "Bullet data",
+                        "name": "Bullet",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/militaries.FuelAddSub"
+                            "$ref": "#/definitions/militaries.BulletAddSub"
                         }
                     }
                 ],
@@ -925,20 +987,23 @@ const docTemplate = `{
                     "500": {
                         "description": "Error while adding quantity",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/fuel/create": {
+        "/bullet/create": {
             "post": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new fuel entry",
+                "description": "Create a new bullet",
                 "consumes": [
                     "application/json"
                 ],
@@ -946,17 +1011,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Fuel"
+                    "Bullet"
                 ],
-                "summary": "Create Fuel",
+                "summary": "Create Bullet",
                 "parameters": [
                     {
-                        "description": "Fuel Request",
-                        "name": "FuelReq",
+                        "description": "Bullet Request",
+                        "name": "BulletReq",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/militaries.FuelReq"
+                            "$ref": "#/definitions/militaries.BulletReq"
                         }
                     }
                 ],
@@ -967,23 +1032,23 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "400": {
+                    "401": {
                         "description": "Error while creating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/fuel/delete/{id}": {
+        "/bullet/delete/{id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing fuel entry",
+                "description": "Delete an existing bullet",
                 "consumes": [
                     "application/json"
                 ],
@@ -991,13 +1056,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Fuel"
+                    "Bullet"
                 ],
-                "summary": "Delete Fuel",
+                "summary": "Delete Bullet",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Fuel ID",
+                        "description": "Bullet ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1010,23 +1075,23 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "400": {
+                    "401": {
                         "description": "Error while deleting",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/fuel/getall": {
+        "/bullet/getall": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all fuel entries",
+                "description": "Get all bullets",
                 "consumes": [
                     "application/json"
                 ],
@@ -1034,10 +1099,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Fuel"
+                    "Bullet"
                 ],
-                "summary": "Get All Fuels",
+                "summary": "Get All Bullets",
                 "parameters": [
+                    {
+                        "type": "number",
+                        "name": "caliber",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "name": "quantity",
@@ -1047,32 +1117,62 @@ const docTemplate = `{
                         "type": "string",
                         "name": "type",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. name,-quantity",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "Get All Successful",
                         "schema": {
-                            "$ref": "#/definitions/militaries.AllFuels"
+                            "$ref": "#/definitions/handler.BulletPage"
                         }
                     },
                     "400": {
+                        "description": "Invalid pagination parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "401": {
                         "description": "Error while getting all",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/fuel/getbyid/{id}": {
+        "/bullet/getbyid/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get an existing fuel entry by ID",
+                "description": "Get an existing bullet by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1080,13 +1180,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Fuel"
+                    "Bullet"
                 ],
-                "summary": "Get Fuel",
+                "summary": "Get Bullet",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Fuel ID",
+                        "description": "Bullet ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1096,26 +1196,26 @@ const docTemplate = `{
                     "200": {
                         "description": "Get Successful",
                         "schema": {
-                            "$ref": "#/definitions/militaries.Fuel"
+                            "$ref": "#/definitions/militaries.Bullet"
                         }
                     },
-                    "400": {
+                    "401": {
                         "description": "Error while getting",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/fuel/sub": {
+        "/bullet/sub": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Subtract quantity from a Fuel",
+                "description": "Subtract quantity from a Bullet",
                 "consumes": [
                     "application/json"
                 ],
@@ -1123,17 +1223,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Fuel"
+                    "Bullet"
                 ],
                 "summary": "Subtract Quantity",
                 "parameters": [
                     {
-                        "description": "Fuel data",
-                        "name": "Fuel",
+                        "description": "Bullet data",
+                        "name": "Bullet",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/militaries.FuelAddSub"
+                            "$ref": "#/definitions/militaries.BulletAddSub"
                         }
                     }
                 ],
@@ -1147,20 +1247,23 @@ const docTemplate = `{
                     "500": {
                         "description": "Error while subtracting quantity",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/fuel/update/{id}": {
+        "/bullet/update/{id}": {
             "put": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing fuel entry",
+                "description": "Update an existing bullet",
                 "consumes": [
                     "application/json"
                 ],
@@ -1168,24 +1271,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Fuel"
+                    "Bullet"
                 ],
-                "summary": "Update Fuel",
+                "summary": "Update Bullet",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Fuel ID",
+                        "description": "Bullet ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Fuel",
-                        "name": "Fuel",
+                        "description": "Bullet",
+                        "name": "Bullet",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/militaries.Fuel"
+                            "$ref": "#/definitions/militaries.Bullet"
                         }
                     }
                 ],
@@ -1196,23 +1299,23 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "400": {
+                    "401": {
                         "description": "Error while updating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/group/create": {
+        "/commander/create": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new group",
+                "description": "Create a new commander",
                 "consumes": [
                     "application/json"
                 ],
@@ -1220,17 +1323,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Group"
+                    "Commander"
                 ],
-                "summary": "Create Group",
+                "summary": "Create Commander",
                 "parameters": [
                     {
-                        "description": "Group Request",
-                        "name": "GroupReq",
+                        "description": "Commander Request",
+                        "name": "CommanderReq",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/soldiers.GroupReq"
+                            "$ref": "#/definitions/soldiers.CreateCommand"
                         }
                     }
                 ],
@@ -1244,20 +1347,20 @@ const docTemplate = `{
                     "401": {
                         "description": "Error while creating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/group/delete/{id}": {
+        "/commander/delete/{id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing group",
+                "description": "Delete an existing commander",
                 "consumes": [
                     "application/json"
                 ],
@@ -1265,13 +1368,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Group"
+                    "Commander"
                 ],
-                "summary": "Delete Group",
+                "summary": "Delete Commander",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Commander ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1287,20 +1390,20 @@ const docTemplate = `{
                     "401": {
                         "description": "Error while deleting",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/group/get/{id}": {
+        "/commander/get/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get an existing group by ID",
+                "description": "Get an existing commander by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1308,13 +1411,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Group"
+                    "Commander"
                 ],
-                "summary": "Get Group",
+                "summary": "Get Commander",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Commander ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1324,26 +1427,26 @@ const docTemplate = `{
                     "200": {
                         "description": "Get Successful",
                         "schema": {
-                            "$ref": "#/definitions/soldiers.Group"
+                            "$ref": "#/definitions/soldiers.Commander"
                         }
                     },
                     "401": {
                         "description": "Error while getting",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/group/getall": {
+        "/commander/getall": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all groups",
+                "description": "Get all commanders",
                 "consumes": [
                     "application/json"
                 ],
@@ -1351,40 +1454,75 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Group"
+                    "Commander"
                 ],
-                "summary": "Get All Groups",
+                "summary": "Get All Commanders",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "name": "email",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "name": "name",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. name,-quantity",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "Get All Successful",
                         "schema": {
-                            "$ref": "#/definitions/soldiers.AllGroups"
+                            "$ref": "#/definitions/handler.CommanderPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
                         }
                     },
                     "401": {
                         "description": "Error while getting all",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/group/update/{id}": {
+        "/commander/update/{id}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing group",
+                "description": "Update an existing commander",
                 "consumes": [
                     "application/json"
                 ],
@@ -1392,24 +1530,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Group"
+                    "Commander"
                 ],
-                "summary": "Update Group",
+                "summary": "Update Commander",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Group ID",
+                        "description": "Commander ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Group",
-                        "name": "Group",
+                        "description": "Commander",
+                        "name": "Commander",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/soldiers.Group"
+                            "$ref": "#/definitions/soldiers.Commander"
                         }
                     }
                 ],
@@ -1423,20 +1561,20 @@ const docTemplate = `{
                     "401": {
                         "description": "Error while updating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/soldier/create": {
+        "/department/create": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new soldier",
+                "description": "Create a new department",
                 "consumes": [
                     "application/json"
                 ],
@@ -1444,17 +1582,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Soldier"
+                    "Department"
                 ],
-                "summary": "Create Soldier",
+                "summary": "Create Department",
                 "parameters": [
                     {
-                        "description": "Soldier Request",
-                        "name": "SoldierReq",
+                        "description": "Department",
+                        "name": "Department",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/soldiers.CreateSoldier"
+                            "$ref": "#/definitions/soldiers.CreateDeportment"
                         }
                     }
                 ],
@@ -1468,68 +1606,20 @@ const docTemplate = `{
                     "401": {
                         "description": "Error while creating",
                         "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/soldier/dashbord": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all Dashbord",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Dashbord"
-                ],
-                "summary": "Get All Dashbord",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Join date of the soldier (format: YYYY-MM-DD)",
-                        "name": "join_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date of the soldier (format: YYYY-MM-DD)",
-                        "name": "end_date",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Get All Successful",
-                        "schema": {
-                            "$ref": "#/definitions/soldiers.AllSoldiers"
-                        }
-                    },
-                    "401": {
-                        "description": "Error while getting all",
-                        "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/soldier/delete/{id}": {
+        "/department/delete/{id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing soldier",
+                "description": "Delete an existing department",
                 "consumes": [
                     "application/json"
                 ],
@@ -1537,13 +1627,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Soldier"
+                    "Department"
                 ],
-                "summary": "Delete Soldier",
+                "summary": "Delete Department",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Soldier ID",
+                        "description": "Department ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1559,20 +1649,20 @@ const docTemplate = `{
                     "401": {
                         "description": "Error while deleting",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/soldier/get/{id}": {
+        "/department/get/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get an existing soldier by ID",
+                "description": "Get an existing department by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1580,13 +1670,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Soldier"
+                    "Department"
                 ],
-                "summary": "Get Soldier",
+                "summary": "Get Department",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Soldier ID",
+                        "description": "Department ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1596,26 +1686,26 @@ const docTemplate = `{
                     "200": {
                         "description": "Get Successful",
                         "schema": {
-                            "$ref": "#/definitions/soldiers.Soldier"
+                            "$ref": "#/definitions/soldiers.Department"
                         }
                     },
                     "401": {
                         "description": "Error while getting",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/soldier/getall": {
+        "/department/getall": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all soldiers",
+                "description": "Get all departments",
                 "consumes": [
                     "application/json"
                 ],
@@ -1623,23 +1713,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Soldier"
+                    "Department"
                 ],
-                "summary": "Get All Soldiers",
+                "summary": "Get All Departments",
                 "parameters": [
                     {
                         "type": "string",
-                        "name": "age",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "name": "email",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "name": "name",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. name,-quantity",
+                        "name": "sort",
                         "in": "query"
                     }
                 ],
@@ -1647,26 +1751,32 @@ const docTemplate = `{
                     "200": {
                         "description": "Get All Successful",
                         "schema": {
-                            "$ref": "#/definitions/soldiers.AllSoldiers"
+                            "$ref": "#/definitions/handler.DepartmentPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
                         }
                     },
                     "401": {
                         "description": "Error while getting all",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/soldier/getallfuelstatistik": {
-            "get": {
+        "/department/update/{id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all fuel statistics for soldiers",
+                "description": "Update an existing department",
                 "consumes": [
                     "application/json"
                 ],
@@ -1674,59 +1784,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Dashbord"
+                    "Department"
                 ],
-                "summary": "Get All Fuel Statistics",
+                "summary": "Update Department",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Date in the format YYYY-MM-DD",
-                        "name": "date",
-                        "in": "query"
+                        "description": "Department ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Soldier ID",
-                        "name": "soldier_id",
-                        "in": "query"
+                        "description": "Department",
+                        "name": "Department",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.Department"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Get All Successful",
-                        "schema": {
-                            "$ref": "#/definitions/soldiers.GetSoldierStatistikFuelRes"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid query parameter",
+                        "description": "Update Successful",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
+                        "description": "Error while updating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/soldier/getallweaponstatistik": {
-            "get": {
+        "/fuel/add": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all weapon statistics for soldiers",
+                "description": "Add quantity to a Fuel",
                 "consumes": [
                     "application/json"
                 ],
@@ -1734,59 +1836,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Dashbord"
+                    "Fuel"
                 ],
-                "summary": "Get All Weapon Statistics",
+                "summary": "Add Quantity",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Date in the format YYYY-MM-DD",
-                        "name": "date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Soldier ID",
-                        "name": "soldier_id",
-                        "in": "query"
+                        "description": "Fuel data",
+                        "name": "Fuel",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/militaries.FuelAddSub"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Get All Successful",
-                        "schema": {
-                            "$ref": "#/definitions/soldiers.GetSoldierStatistikRes"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid query parameter",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                        "description": "Add Successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/militaries.Void"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Error while adding quantity",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/soldier/update/{id}": {
-            "put": {
+        "/fuel/create": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing soldier",
+                "description": "Create a new fuel entry",
                 "consumes": [
                     "application/json"
                 ],
@@ -1794,51 +1881,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Soldier"
+                    "Fuel"
                 ],
-                "summary": "Update Soldier",
+                "summary": "Create Fuel",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Soldier ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Soldier",
-                        "name": "Soldier",
+                        "description": "Fuel Request",
+                        "name": "FuelReq",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/soldiers.Soldier"
+                            "$ref": "#/definitions/militaries.FuelReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Update Successful",
+                        "description": "Create Successful",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Error while updating",
+                    "400": {
+                        "description": "Error while creating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Problem"
                         }
                     }
                 }
             }
         },
-        "/soldier/usebullet": {
-            "post": {
+        "/fuel/delete/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Record the use of bullets by a soldier",
+                "description": "Delete an existing fuel entry",
                 "consumes": [
                     "application/json"
                 ],
@@ -1846,44 +1926,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Soldier"
+                    "Fuel"
                 ],
-                "summary": "Use Bullet",
+                "summary": "Delete Fuel",
                 "parameters": [
                     {
-                        "description": "Use Bullet",
-                        "name": "UseB",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/soldiers.UseB"
-                        }
+                        "type": "string",
+                        "description": "Fuel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Use Bullet Successful",
+                        "description": "Delete Successful",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Error while using bullet",
+                    "400": {
+                        "description": "Error while deleting",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/soldier/usefuel": {
-            "post": {
+        "/fuel/getall": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Record the use of fuel by a soldier",
+                "description": "Get all fuel entries",
                 "consumes": [
                     "application/json"
                 ],
@@ -1891,44 +1969,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Soldier"
+                    "Fuel"
                 ],
-                "summary": "Use Fuel",
+                "summary": "Get All Fuels",
                 "parameters": [
                     {
-                        "description": "Use Fuel",
-                        "name": "UseF",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/soldiers.UseF"
-                        }
+                        "type": "integer",
+                        "name": "quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. name,-quantity",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Use Fuel Successful",
+                        "description": "Get All Successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handler.FuelPage"
                         }
                     },
-                    "401": {
-                        "description": "Error while using fuel",
+                    "400": {
+                        "description": "Error while getting all",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/technique/add": {
-            "put": {
+        "/fuel/getbyid/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Add quantity to a technique",
+                "description": "Get an existing fuel entry by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1936,44 +2039,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Technique"
+                    "Fuel"
                 ],
-                "summary": "Add Quantity",
+                "summary": "Get Fuel",
                 "parameters": [
                     {
-                        "description": "Technique data",
-                        "name": "technique",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/militaries.TechniqueAddSub"
-                        }
+                        "type": "string",
+                        "description": "Fuel ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Add Successful",
+                        "description": "Get Successful",
                         "schema": {
-                            "$ref": "#/definitions/militaries.Void"
+                            "$ref": "#/definitions/militaries.Fuel"
                         }
                     },
-                    "500": {
-                        "description": "Error while adding quantity",
+                    "400": {
+                        "description": "Error while getting",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/technique/create": {
-            "post": {
+        "/fuel/sub": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new technique entry",
+                "description": "Subtract quantity from a Fuel",
                 "consumes": [
                     "application/json"
                 ],
@@ -1981,44 +2082,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Technique"
+                    "Fuel"
                 ],
-                "summary": "Create Technique",
+                "summary": "Subtract Quantity",
                 "parameters": [
                     {
-                        "description": "Technique Request",
-                        "name": "TechniqueReq",
+                        "description": "Fuel data",
+                        "name": "Fuel",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/militaries.TechniqueReq"
+                            "$ref": "#/definitions/militaries.FuelAddSub"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Create Successful",
+                        "description": "Subtract Successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/militaries.Void"
                         }
                     },
-                    "400": {
-                        "description": "Error while creating",
+                    "500": {
+                        "description": "Error while subtracting quantity",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/technique/delete/{id}": {
-            "delete": {
+        "/fuel/update/{id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing technique entry",
+                "description": "Update an existing fuel entry",
                 "consumes": [
                     "application/json"
                 ],
@@ -2026,42 +2127,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Technique"
+                    "Fuel"
                 ],
-                "summary": "Delete Technique",
+                "summary": "Update Fuel",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Technique ID",
+                        "description": "Fuel ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Fuel",
+                        "name": "Fuel",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/militaries.Fuel"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Delete Successful",
+                        "description": "Update Successful",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Error while deleting",
+                        "description": "Error while updating",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Problem"
                         }
                     }
                 }
             }
         },
-        "/technique/getall": {
-            "get": {
+        "/group/create": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all technique entries",
+                "description": "Create a new group",
                 "consumes": [
                     "application/json"
                 ],
@@ -2069,50 +2179,87 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Technique"
+                    "Group"
                 ],
-                "summary": "Get All Techniques",
+                "summary": "Create Group",
                 "parameters": [
                     {
-                        "type": "string",
-                        "name": "model",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "name": "quantity",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "type",
-                        "in": "query"
+                        "description": "Group Request",
+                        "name": "GroupReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.GroupReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Get All Successful",
+                        "description": "Create Successful",
                         "schema": {
-                            "$ref": "#/definitions/militaries.AllTechnique"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Error while getting all",
-                        "schema": {
+                    "401": {
+                        "description": "Error while creating",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/group/delete/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an existing group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Group"
+                ],
+                "summary": "Delete Group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delete Successful",
+                        "schema": {
                             "type": "string"
                         }
+                    },
+                    "401": {
+                        "description": "Error while deleting",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
                     }
                 }
             }
         },
-        "/technique/getbyid/{id}": {
+        "/group/get/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get an existing technique entry by ID",
+                "description": "Get an existing group by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -2120,13 +2267,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Technique"
+                    "Group"
                 ],
-                "summary": "Get Technique",
+                "summary": "Get Group",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Technique ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2136,158 +2283,1752 @@ const docTemplate = `{
                     "200": {
                         "description": "Get Successful",
                         "schema": {
-                            "$ref": "#/definitions/militaries.Technique"
+                            "$ref": "#/definitions/soldiers.Group"
                         }
                     },
-                    "400": {
+                    "401": {
                         "description": "Error while getting",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/group/getall": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all groups",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Group"
+                ],
+                "summary": "Get All Groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. name,-quantity",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Get All Successful",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GroupPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while getting all",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/group/update/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an existing group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Group"
+                ],
+                "summary": "Update Group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Group",
+                        "name": "Group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.Group"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Update Successful",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "401": {
+                        "description": "Error while updating",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Same report as /readyz, but only fails with 503 when every backend is down",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health",
+                "responses": {
+                    "200": {
+                        "description": "Healthy or degraded",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Always succeeds while the process can serve HTTP; backends are not probed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness",
+                "responses": {
+                    "200": {
+                        "description": "Alive",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Probes the connection state and grpc.health.v1 service of every backend. Returns 503 when any backend is down",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness",
+                "responses": {
+                    "200": {
+                        "description": "Ready",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/create": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new soldier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Soldier"
+                ],
+                "summary": "Create Soldier",
+                "parameters": [
+                    {
+                        "description": "Soldier Request",
+                        "name": "SoldierReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.CreateSoldier"
+                        }
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Create Successful",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while creating",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/dashbord": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all Dashbord",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashbord"
+                ],
+                "summary": "Get All Dashbord",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Join date of the soldier (format: YYYY-MM-DD)",
+                        "name": "join_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date of the soldier (format: YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Get All Successful",
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.AllSoldiers"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while getting all",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/delete/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an existing soldier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Soldier"
+                ],
+                "summary": "Delete Soldier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Soldier ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delete Successful",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while deleting",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/get/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an existing soldier by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Soldier"
+                ],
+                "summary": "Get Soldier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Soldier ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Get Successful",
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.Soldier"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while getting",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/getall": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all soldiers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Soldier"
+                ],
+                "summary": "Get All Soldiers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. name,-quantity",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Get All Successful",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SoldierPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while getting all",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/getallfuelstatistik": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all fuel statistics for soldiers. Commanders only get the statistics of the soldiers of their departments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashbord"
+                ],
+                "summary": "Get All Fuel Statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Date in the format YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Soldier ID",
+                        "name": "soldier_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Get All Successful",
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.GetSoldierStatistikFuelRes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Soldier is outside of your department",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/getallweaponstatistik": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all weapon statistics for soldiers. Commanders only get the statistics of the soldiers of their departments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Dashbord"
+                ],
+                "summary": "Get All Weapon Statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Date in the format YYYY-MM-DD",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Soldier ID",
+                        "name": "soldier_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Get All Successful",
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.GetSoldierStatistikRes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Soldier is outside of your department",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/update/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an existing soldier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Soldier"
+                ],
+                "summary": "Update Soldier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Soldier ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Soldier",
+                        "name": "Soldier",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.Soldier"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Update Successful",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while updating",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/usebullet": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record the use of bullets by a soldier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Soldier"
+                ],
+                "summary": "Use Bullet",
+                "parameters": [
+                    {
+                        "description": "Use Bullet",
+                        "name": "UseB",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.UseB"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Use Bullet Successful",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while using bullet",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/soldier/usefuel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record the use of fuel by a soldier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Soldier"
+                ],
+                "summary": "Use Fuel",
+                "parameters": [
+                    {
+                        "description": "Use Fuel",
+                        "name": "UseF",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/soldiers.UseF"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Use Fuel Successful",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Error while using fuel",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/technique/add": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add quantity to a technique",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Technique"
+                ],
+                "summary": "Add Quantity",
+                "parameters": [
+                    {
+                        "description": "Technique data",
+                        "name": "technique",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/militaries.TechniqueAddSub"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Add Successful",
+                        "schema": {
+                            "$ref": "#/definitions/militaries.Void"
+                        }
+                    },
+                    "500": {
+                        "description": "Error while adding quantity",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/technique/create": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new technique entry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Technique"
+                ],
+                "summary": "Create Technique",
+                "parameters": [
+                    {
+                        "description": "Technique Request",
+                        "name": "TechniqueReq",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/militaries.TechniqueReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Create Successful",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Error while creating",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/technique/delete/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an existing technique entry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Technique"
+                ],
+                "summary": "Delete Technique",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Technique ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delete Successful",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Error while deleting",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/technique/getall": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all technique entries",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Technique"
+                ],
+                "summary": "Get All Techniques",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields, - for descending, e.g. name,-quantity",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Get All Successful",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TechniquePage"
+                        }
+                    },
+                    "400": {
+                        "description": "Error while getting all",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/technique/getbyid/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an existing technique entry by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Technique"
+                ],
+                "summary": "Get Technique",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Technique ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Get Successful",
+                        "schema": {
+                            "$ref": "#/definitions/militaries.Technique"
+                        }
+                    },
+                    "400": {
+                        "description": "Error while getting",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/technique/sub": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subtract quantity from a technique",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Technique"
+                ],
+                "summary": "Subtract Quantity",
+                "parameters": [
+                    {
+                        "description": "Technique data",
+                        "name": "technique",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/militaries.TechniqueAddSub"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subtract Successful",
+                        "schema": {
+                            "$ref": "#/definitions/militaries.Void"
+                        }
+                    },
+                    "500": {
+                        "description": "Error while subtracting quantity",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/technique/update/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an existing technique entry",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Technique"
+                ],
+                "summary": "Update Technique",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Technique ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Technique",
+                        "name": "Technique",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/militaries.Technique"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Update Successful",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Error while updating",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "ai.AiCHat": {
+            "type": "object",
+            "properties": {
+                "system_context": {
+                    "description": "The live data the reply is grounded on, as JSON. Set by the gateway.",
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "thread_id": {
+                    "description": "The gateway thread the message belongs to. Set by the gateway.",
+                    "type": "string"
+                },
+                "tool_calls": {
+                    "description": "In a reply, the calls the AI needs answered before it can finish. The\ngateway sends the message again with their results.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.ToolCall"
+                    }
+                },
+                "tool_results": {
+                    "description": "The results of every call made so far for this message. Set by the\ngateway.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.ToolResult"
+                    }
+                },
+                "tools": {
+                    "description": "The tools the AI may call for the user. Set by the gateway.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.Tool"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai.GetAllAi": {
+            "type": "object",
+            "properties": {
+                "request_text": {
+                    "type": "string"
+                },
+                "response_text": {
+                    "type": "string"
+                },
+                "time": {
+                    "description": "When the message was sent, in RFC 3339. The gateway filters the\nhistory by it.",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.Tool": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "description": "JSON schema of the arguments.",
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ToolCall": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "description": "The arguments as a JSON object.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai.ToolResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "The JSON error response of a failed call.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "result": {
+                    "description": "The JSON result of the call.",
+                    "type": "string"
+                }
+            }
+        },
+        "apierror.InvalidParam": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "apierror.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "invalid-params": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apierror.InvalidParam"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "apierror.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Record": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "object"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "request": {
+                    "type": "object"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string"
+                },
+                "seq": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.BulletPage": {
+            "type": "object",
+            "properties": {
+                "bullets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/militaries.Bullet"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CommanderPage": {
+            "type": "object",
+            "properties": {
+                "commanders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/soldiers.Commander"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.DepartmentPage": {
+            "type": "object",
+            "properties": {
+                "departments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/soldiers.Department"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.FuelPage": {
+            "type": "object",
+            "properties": {
+                "fuels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/militaries.Fuel"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.GroundingContext": {
+            "type": "object",
+            "properties": {
+                "bullets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.Supply"
+                    }
+                },
+                "fuels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.Supply"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "personnel": {
+                    "$ref": "#/definitions/handler.Personnel"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "departments"
+                    ]
+                },
+                "techniques": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.Supply"
+                    }
+                },
+                "unavailable": {
+                    "description": "Unavailable names the sections whose backend could not be reached.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "window_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.GroupPage": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/soldiers.Group"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.HistoryPage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai.GetAllAi"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.LoginReq": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LogoutReq": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.Personnel": {
+            "type": "object",
+            "properties": {
+                "departments": {
+                    "type": "integer"
+                },
+                "groups": {
+                    "type": "integer"
+                },
+                "soldiers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.RefreshReq": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SoldierPage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "soldiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/soldiers.Soldier"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.StreamFrame": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
                 }
             }
         },
-        "/technique/sub": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Subtract quantity from a technique",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Technique"
-                ],
-                "summary": "Subtract Quantity",
-                "parameters": [
-                    {
-                        "description": "Technique data",
-                        "name": "technique",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/militaries.TechniqueAddSub"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Subtract Successful",
-                        "schema": {
-                            "$ref": "#/definitions/militaries.Void"
-                        }
-                    },
-                    "500": {
-                        "description": "Error while subtracting quantity",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
+        "handler.Supply": {
+            "type": "object",
+            "properties": {
+                "daily_use": {
+                    "type": "number"
+                },
+                "days_left": {
+                    "type": "number"
+                },
+                "stock": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "used_in_window": {
+                    "type": "integer"
                 }
             }
         },
-        "/technique/update/{id}": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update an existing technique entry",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Technique"
-                ],
-                "summary": "Update Technique",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Technique ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Technique",
-                        "name": "Technique",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/militaries.Technique"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Update Successful",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Error while updating",
-                        "schema": {
-                            "type": "string"
-                        }
+        "handler.TechniquePage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "techniques": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/militaries.Technique"
                     }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
-        }
-    },
-    "definitions": {
-        "ai.AiCHat": {
+        },
+        "handler.ThreadHistoryPage": {
             "type": "object",
             "properties": {
-                "text": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
                 },
-                "user_id": {
+                "offset": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/thread.Message"
+                    }
+                },
+                "thread_id": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "militaries.AllBullets": {
+        "handler.ThreadPage": {
             "type": "object",
             "properties": {
-                "bullets": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "threads": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/militaries.Bullet"
+                        "$ref": "#/definitions/thread.Thread"
                     }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "militaries.AllFuels": {
+        "handler.ThreadReq": {
             "type": "object",
+            "required": [
+                "title"
+            ],
             "properties": {
-                "fuels": {
+                "title": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "handler.Tool": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "object"
+                },
+                "route": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ToolList": {
+            "type": "object",
+            "properties": {
+                "tools": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/militaries.Fuel"
+                        "$ref": "#/definitions/handler.Tool"
                     }
                 }
             }
         },
-        "militaries.AllTechnique": {
+        "health.Check": {
             "type": "object",
             "properties": {
-                "techniques": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/militaries.Technique"
+                "health": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Check"
                     }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
                 }
             }
         },
@@ -2311,7 +4052,7 @@ const docTemplate = `{
         "militaries.BulletAddSub": {
             "type": "object",
             "properties": {
-                "id": {
+                "name": {
                     "type": "string"
                 },
                 "quantity": {
@@ -2350,7 +4091,7 @@ const docTemplate = `{
         "militaries.FuelAddSub": {
             "type": "object",
             "properties": {
-                "id": {
+                "name": {
                     "type": "string"
                 },
                 "quantity": {
@@ -2389,7 +4130,7 @@ const docTemplate = `{
         "militaries.TechniqueAddSub": {
             "type": "object",
             "properties": {
-                "id": {
+                "name": {
                     "type": "string"
                 },
                 "quantity": {
@@ -2414,39 +4155,6 @@ const docTemplate = `{
         "militaries.Void": {
             "type": "object"
         },
-        "soldiers.AllCommanders": {
-            "type": "object",
-            "properties": {
-                "commanders": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/soldiers.Commander"
-                    }
-                }
-            }
-        },
-        "soldiers.AllDepartments": {
-            "type": "object",
-            "properties": {
-                "departments": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/soldiers.Department"
-                    }
-                }
-            }
-        },
-        "soldiers.AllGroups": {
-            "type": "object",
-            "properties": {
-                "groups": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/soldiers.Group"
-                    }
-                }
-            }
-        },
         "soldiers.AllSoldiers": {
             "type": "object",
             "properties": {
@@ -2658,12 +4366,64 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "thread.Message": {
+            "type": "object",
+            "properties": {
+                "request_text": {
+                    "type": "string"
+                },
+                "response_text": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "thread.Thread": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "messages": {
+                    "type": "integer"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "token.Tokens": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
         "BearerAuth": {
+            "description": "Type \"Bearer\" followed by a space and the access token.",
             "type": "apiKey",
-            "name": "Authourization",
+            "name": "Authorization",
             "in": "header"
         }
     }
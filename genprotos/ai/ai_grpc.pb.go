@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.3.0
-// - protoc             v3.12.4
+// - protoc             (unknown)
 // source: ai.proto
 
 package ai
@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion7
 const (
 	AiService_CHat_FullMethodName       = "/AI.AiService/CHat"
 	AiService_GetHistory_FullMethodName = "/AI.AiService/GetHistory"
+	AiService_CHatStream_FullMethodName = "/AI.AiService/CHatStream"
 )
 
 // AiServiceClient is the client API for AiService service.
@@ -29,6 +30,8 @@ const (
 type AiServiceClient interface {
 	CHat(ctx context.Context, in *AiCHat, opts ...grpc.CallOption) (*AiCHat, error)
 	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// CHatStream sends the reply to a message in parts as it is generated.
+	CHatStream(ctx context.Context, in *AiCHat, opts ...grpc.CallOption) (AiService_CHatStreamClient, error)
 }
 
 type aiServiceClient struct {
@@ -57,12 +60,46 @@ func (c *aiServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest,
 	return out, nil
 }
 
+func (c *aiServiceClient) CHatStream(ctx context.Context, in *AiCHat, opts ...grpc.CallOption) (AiService_CHatStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AiService_ServiceDesc.Streams[0], AiService_CHatStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aiServiceCHatStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AiService_CHatStreamClient interface {
+	Recv() (*AiCHat, error)
+	grpc.ClientStream
+}
+
+type aiServiceCHatStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *aiServiceCHatStreamClient) Recv() (*AiCHat, error) {
+	m := new(AiCHat)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // AiServiceServer is the server API for AiService service.
 // All implementations must embed UnimplementedAiServiceServer
 // for forward compatibility
 type AiServiceServer interface {
 	CHat(context.Context, *AiCHat) (*AiCHat, error)
 	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// CHatStream sends the reply to a message in parts as it is generated.
+	CHatStream(*AiCHat, AiService_CHatStreamServer) error
 	mustEmbedUnimplementedAiServiceServer()
 }
 
@@ -76,6 +113,9 @@ func (UnimplementedAiServiceServer) CHat(context.Context, *AiCHat) (*AiCHat, err
 func (UnimplementedAiServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
 }
+func (UnimplementedAiServiceServer) CHatStream(*AiCHat, AiService_CHatStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method CHatStream not implemented")
+}
 func (UnimplementedAiServiceServer) mustEmbedUnimplementedAiServiceServer() {}
 
 // UnsafeAiServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -125,6 +165,27 @@ func _AiService_GetHistory_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AiService_CHatStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AiCHat)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AiServiceServer).CHatStream(m, &aiServiceCHatStreamServer{stream})
+}
+
+type AiService_CHatStreamServer interface {
+	Send(*AiCHat) error
+	grpc.ServerStream
+}
+
+type aiServiceCHatStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *aiServiceCHatStreamServer) Send(m *AiCHat) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // AiService_ServiceDesc is the grpc.ServiceDesc for AiService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -141,6 +202,12 @@ var AiService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _AiService_GetHistory_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CHatStream",
+			Handler:       _AiService_CHatStream_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "ai.proto",
 }
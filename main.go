@@ -1,35 +1,94 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Salikhov079/military/api"
+	"github.com/Salikhov079/military/api/audit"
+	"github.com/Salikhov079/military/api/cache"
 	"github.com/Salikhov079/military/api/handler"
+	"github.com/Salikhov079/military/api/health"
+	"github.com/Salikhov079/military/api/idempotency"
+	"github.com/Salikhov079/military/api/logging"
+	"github.com/Salikhov079/military/api/metrics"
+	"github.com/Salikhov079/military/api/middleware"
+	"github.com/Salikhov079/military/api/pagination"
+	"github.com/Salikhov079/military/api/ratelimit"
+	"github.com/Salikhov079/military/api/redis"
+	"github.com/Salikhov079/military/api/saga"
+	"github.com/Salikhov079/military/api/stream"
+	"github.com/Salikhov079/military/api/thread"
+	"github.com/Salikhov079/military/api/token"
+	"github.com/Salikhov079/military/api/tracing"
+	"github.com/Salikhov079/military/config"
 	ai "github.com/Salikhov079/military/genprotos/ai"
 	pb "github.com/Salikhov079/military/genprotos/militaries"
 	pbs "github.com/Salikhov079/military/genprotos/soldiers"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
 )
 
 func main() {
-	mil, err := grpc.NewClient(fmt.Sprintf("localhost%s", ":8085"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	cfg := config.Load()
+
+	logger, err := logging.New(os.Stdout, logging.Options{Level: cfg.LogLevel, SampleRate: cfg.LogSampleRate})
+	if err != nil {
+		fatal("Error while setting up logging", err)
+	}
+	slog.SetDefault(logger)
+	if err := cfg.Check(); err != nil {
+		fatal("Error while checking config", err)
+	}
+	// gin's debug output is plain text; keep stdout JSON unless GIN_MODE asks.
+	if _, ok := os.LookupEnv(gin.EnvGinMode); !ok {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		ServiceName: cfg.ServiceName,
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		File:        cfg.TracingFile,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		fatal("Error while setting up tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Error while flushing traces", "error", err)
+		}
+	}()
+
+	mil, err := dial(cfg.Militaries)
 	if err != nil {
-		log.Fatal("Error while NEwclient: ", err.Error())
+		fatal("Error while NEwclient", err)
 	}
 	defer mil.Close()
 
-	sol, err := grpc.NewClient(fmt.Sprintf("localhost%s", ":7070"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	sol, err := dial(cfg.Soldiers)
 	if err != nil {
-		log.Fatal("Error while NEwclient: ", err.Error())
+		fatal("Error while NEwclient", err)
 	}
 	defer sol.Close()
 
-	a, err := grpc.NewClient(fmt.Sprintf("localhost%s", ":8086"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	a, err := dial(cfg.AI)
 	if err != nil {
-		log.Fatal("Error while NEwclient: ", err.Error())
+		fatal("Error while NEwclient", err)
 	}
 	defer a.Close()
 
@@ -42,12 +101,209 @@ func main() {
 	so := pbs.NewSoldierServiceClient(sol)
 	ai := ai.NewAiServiceClient(a)
 
-	h := handler.NewHandler(c, ps, ca, el, py, us, so, ai)
-	r := api.NewGin(h)
+	policy, err := middleware.LoadPolicy(cfg.PolicyPath)
+	if err != nil {
+		fatal("Error while loading policy", err)
+	}
+	passwords, err := token.LoadPasswords(cfg.CredentialsPath)
+	if err != nil {
+		fatal("Error while loading credentials", err)
+	}
+	cr := handler.NewCommanderStore(el, passwords)
+
+	token.Configure(token.Options{
+		Key:        cfg.TokenKey,
+		Algorithms: cfg.JWTAlgorithms,
+		AccessTTL:  cfg.AccessTokenTTL,
+		RefreshTTL: cfg.RefreshTokenTTL,
+	})
+	if cfg.JWKSSource != "" {
+		keys, err := token.LoadKeySet(cfg.JWKSSource)
+		if err != nil {
+			fatal("Error while loading jwks", err)
+		}
+		defer keys.Watch(cfg.JWKSRefresh)()
+		token.SetKeySet(keys)
+	}
+	if cfg.SigningKeyPath != "" {
+		if err := token.LoadSigningKey(cfg.SigningKeyPath, cfg.SigningKeyID); err != nil {
+			fatal("Error while loading signing key", err)
+		}
+	} else if !strings.Contains(cfg.JWTAlgorithms, "HS256") {
+		slog.Warn("No signing key is configured and HS256 is not allowed, so tokens issued by /auth/login will be rejected", "jwt_algorithms", cfg.JWTAlgorithms)
+	}
+	if err := token.CheckConfig(); err != nil {
+		fatal("Error while checking token configuration", err)
+	}
 
-	fmt.Println("Server started on port:8080")
-	err = r.Run()
+	au, err := audit.Open(cfg.AuditLogPath)
 	if err != nil {
-		log.Fatal("Error while Run: ", err.Error())
+		fatal("Error while opening audit log", err)
+	}
+	defer au.Close()
+
+	journal, err := saga.OpenJournal(cfg.SagaJournalPath)
+	if err != nil {
+		fatal("Error while opening saga journal", err)
+	}
+	defer journal.Close()
+	sg := saga.New(journal)
+
+	limits := thread.Limits{Threads: cfg.AIThreadMaxThreads, Messages: cfg.AIThreadMaxMessages}
+	threads := thread.NewMemoryStore(limits)
+	if cfg.AIThreadStore == "file" {
+		threads, err = thread.NewFileStore(cfg.AIThreadFile, limits)
+		if err != nil {
+			fatal("Error while opening AI thread store", err)
+		}
+	}
+	if c, ok := threads.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	hub := stream.NewHub(cfg.AIStreamResumeWindow, cfg.AIStreamHeartbeat)
+	h := handler.NewHandler(c, ps, ca, el, py, us, so, ai, cr, au, sg, hub, threads, policy)
+	if err := sg.Recover(); err != nil {
+		fatal("Error while recovering sagas", err)
 	}
+	idem := idempotency.NewMemoryStore(cfg.IdempotencyMaxEntries)
+	if cfg.IdempotencyStore == "file" {
+		idem, err = idempotency.NewFileStore(cfg.IdempotencyFile, cfg.IdempotencyMaxEntries)
+		if err != nil {
+			fatal("Error while opening idempotency store", err)
+		}
+	}
+	if c, ok := idem.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	hc := health.New(cfg.HealthTimeout,
+		health.Dependency{Name: "militaries", Conn: mil},
+		health.Dependency{Name: "soldiers", Conn: sol},
+		health.Dependency{Name: "ai", Conn: a},
+	)
+
+	var rc *redis.Client
+	if cfg.CacheStore == "redis" || cfg.RateLimitStore == "redis" {
+		rc, err = redis.Dial(redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+			Timeout:  cfg.RedisTimeout,
+		})
+		if err != nil {
+			fatal("Error while connecting to redis", err)
+		}
+		defer rc.Close()
+	}
+
+	var cs cache.Store
+	switch cfg.CacheStore {
+	case "memory":
+		cs = cache.NewLRUStore(cfg.CacheSize)
+	case "redis":
+		cs = cache.NewRedisStore(rc)
+	}
+
+	var rl *ratelimit.Limiter
+	if cfg.RateLimitStore != "none" {
+		ls := ratelimit.NewMemoryStore()
+		if cfg.RateLimitStore == "redis" {
+			ls = ratelimit.NewRedisStore(rc)
+		}
+		rl, err = ratelimit.New(ls, cfg.RateLimits)
+		if err != nil {
+			fatal("Error while configuring rate limits", err)
+		}
+	}
+
+	pagination.SetDefaults(cast.ToInt(cfg.DefaultLimit), cast.ToInt(cfg.DefaultOffset))
+	handler.SetStreamOrigins(list(cfg.AIStreamOrigins))
+	metrics.SetTechniqueTypes(list(cfg.MetricsTechniqueTypes))
+
+	r := api.NewGin(h, policy, cfg, idem, hc, cs, rl)
+	// Without trusted proxies ClientIP is the peer address, so clients
+	// cannot pick their rate limit bucket with X-Forwarded-For.
+	if err := r.SetTrustedProxies(list(cfg.TrustedProxies)); err != nil {
+		fatal("Error while setting trusted proxies", err)
+	}
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPPort,
+		Handler:           r,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	var ms *http.Server
+	if cfg.MetricsPort != "" {
+		ln, err := net.Listen("tcp", cfg.MetricsPort)
+		if err != nil {
+			fatal("Error while listening for metrics", err)
+		}
+		ms = metrics.NewServer(cfg.ReadHeaderTimeout)
+		go func() {
+			slog.Info("Metrics server started", "addr", cfg.MetricsPort)
+			if err := ms.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Error while serving metrics", "error", err)
+			}
+		}()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("Server started", "addr", cfg.HTTPPort)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		fatal("Error while Run", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	// End the AI chat streams, whose WebSockets and detached producers the
+	// server does not track, then stop accepting connections and let
+	// in-flight requests, sagas included, finish. The deferred calls then
+	// flush the audit log and saga journal before the gRPC connections are
+	// closed.
+	slog.Info("Shutting down, draining requests", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := hub.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error while closing AI chat streams", "error", err)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error while Shutdown", "error", err)
+	}
+	if err := <-errc; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Error while Run", "error", err)
+	}
+	if ms != nil {
+		ms.Close()
+	}
+	slog.Info("Server stopped")
+}
+
+// list splits a comma-separated setting, dropping empty items.
+func list(s string) []string {
+	var res []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// fatal logs err and exits. Deferred calls do not run.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
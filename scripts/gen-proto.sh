@@ -1,7 +1,12 @@
 #!/bin/bash
 CURRENT_DIR=$1
 rm -rf ${CURRENT_DIR}/genprotos
-for x in $(find ${CURRENT_DIR}/military-submodule/* -type d); do
+# A directory under protos/ replaces the submodule's directory of the same
+# name; it holds proto changes the submodule has not released yet.
+for x in $(find ${CURRENT_DIR}/military-submodule/* ${CURRENT_DIR}/protos/* -type d); do
+  if [[ $x == ${CURRENT_DIR}/military-submodule/* && -d ${CURRENT_DIR}/protos/$(basename ${x}) ]]; then
+    continue
+  fi
   protoc -I=${x} -I=${CURRENT_DIR}/protos -I /usr/local/go --go_out=${CURRENT_DIR} \
    --go-grpc_out=${CURRENT_DIR} ${x}/*.proto
 done